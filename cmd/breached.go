@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/anfimovoleh/ms-users/password"
+)
+
+// BuildBreachedFilter reads the dump twice, first to size the filter
+// for the amount of hashes and then to fill it
+func BuildBreachedFilter(path string, falsePositiveRate float64) (*password.BloomFilter, error) {
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, errors.New("false positive rate must be between 0 and 1")
+	}
+
+	dump, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open dump")
+	}
+	defer dump.Close()
+
+	count, err := countLines(dump)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dump.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "failed to rewind dump")
+	}
+
+	return password.BuildBloomFilter(dump, count, falsePositiveRate)
+}
+
+func WriteBreachedFilter(path string, filter *password.BloomFilter) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create filter file")
+	}
+
+	if _, err := filter.WriteTo(file); err != nil {
+		file.Close()
+		return errors.Wrap(err, "failed to write filter")
+	}
+
+	return file.Close()
+}
+
+// countLines is an upper bound of the amount of hashes, the last line may
+// have no newline
+func countLines(r io.Reader) (uint64, error) {
+	var count uint64
+
+	reader := bufio.NewReader(r)
+	buffer := make([]byte, 64*1024)
+	for {
+		n, err := reader.Read(buffer)
+		count += uint64(bytes.Count(buffer[:n], []byte{'\n'}))
+		if err == io.EOF {
+			return count + 1, nil
+		}
+
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to read dump")
+		}
+	}
+}
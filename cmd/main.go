@@ -7,6 +7,8 @@ import (
 
 	app "github.com/anfimovoleh/ms-users"
 	"github.com/anfimovoleh/ms-users/config"
+	"github.com/anfimovoleh/ms-users/signing"
+	"github.com/lestrrat-go/jwx/jwa"
 	"github.com/pkg/errors"
 	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
@@ -78,7 +80,146 @@ func main() {
 		},
 	}
 
-	rootCmd.AddCommand(runCmd, migrateCmd)
+	revokeSessionsCmd := &cobra.Command{
+		Use:   "revoke-sessions [EMAIL]",
+		Short: "log user out everywhere",
+		Long:  "invalidates every access and refresh token issued to the user",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			log := log.With(
+				zap.String("service", "revoke-sessions"),
+				zap.String("email", args[0]),
+			)
+
+			user, err := apiConfig.DB().GetUser(args[0])
+			if err != nil {
+				log.With(zap.Error(err)).Error("failed to get user")
+				return
+			}
+
+			if err := apiConfig.DB().RevokeUserSessions(user.ID); err != nil {
+				log.With(zap.Error(err)).Error("failed to revoke user sessions")
+				return
+			}
+			log.Info("user sessions revoked")
+		},
+	}
+
+	adminCmd := &cobra.Command{
+		Use:   "admin [grant|revoke] [EMAIL]",
+		Short: "manage admin permissions",
+		Long:  "grants or revokes the admin permissions of the user",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			log := log.With(
+				zap.String("service", "admin"),
+				zap.String("email", args[1]),
+			)
+
+			var isAdmin bool
+			switch args[0] {
+			case "grant":
+				isAdmin = true
+			case "revoke":
+				isAdmin = false
+			default:
+				log.With(zap.String("action", args[0])).Error("unknown action")
+				return
+			}
+
+			user, err := apiConfig.DB().GetUser(args[1])
+			if err != nil {
+				log.With(zap.Error(err)).Error("failed to get user")
+				return
+			}
+
+			if err := apiConfig.DB().SetUserAdmin(user.ID, isAdmin); err != nil {
+				log.With(zap.Error(err)).Error("failed to update admin permissions")
+				return
+			}
+			log.With(zap.Bool("is_admin", isAdmin)).Info("admin permissions updated")
+		},
+	}
+
+	keysCmd := &cobra.Command{
+		Use:   "keys",
+		Short: "manage signing keys",
+	}
+
+	var algorithm string
+	keysRotateCmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "rotate the signing key",
+		Long: "generates a new signing key and promotes it once every replica and JWKS consumer knows it: " +
+			"it is published for verification right away and signs tokens after USERS_AUTHENTICATION_KEYS_RELOAD_INTERVAL " +
+			"plus the " + signing.JWKSMaxAge.String() + " JWKS cache max age, the services switch to it on their own. " +
+			"The first key signs tokens right away, the previous key is kept to verify the tokens it signed",
+		Run: func(cmd *cobra.Command, args []string) {
+			log := log.With(zap.String("service", "keys-rotate"))
+
+			authentication := apiConfig.Authentication()
+			keystore := authentication.Keystore()
+			if keystore == nil {
+				log.Error("keystore is not configured, set USERS_AUTHENTICATION_KEYS_DIR")
+				return
+			}
+
+			//replicas publish the key on their next reload, and the JWKS
+			//consumers may cache the previous key set for JWKSMaxAge
+			delay := authentication.KeysReloadInterval + signing.JWKSMaxAge
+			//replicas keep signing with the retired key until their next reload
+			retention := authentication.AccessTokenTTL + authentication.KeysReloadInterval
+			key, activatesAt, err := keystore.Rotate(jwa.SignatureAlgorithm(algorithm), delay, retention)
+			if err != nil {
+				log.With(zap.Error(err)).Error("failed to rotate signing key")
+				return
+			}
+			log.With(
+				zap.String("kid", key.ID),
+				zap.String("algorithm", key.Algorithm.String()),
+				zap.Time("activates_at", activatesAt),
+			).Info("signing key published")
+		},
+	}
+	keysRotateCmd.Flags().StringVar(&algorithm, "algorithm", jwa.EdDSA.String(), "algorithm of the new key: EdDSA, ES256, ES384, ES512 or RS256")
+	keysCmd.AddCommand(keysRotateCmd)
+
+	breachedCmd := &cobra.Command{
+		Use:   "breached",
+		Short: "manage breached passwords corpus",
+	}
+
+	var output string
+	var falsePositiveRate float64
+	breachedBuildCmd := &cobra.Command{
+		Use:   "build [DUMP]",
+		Short: "build breached passwords filter",
+		Long:  "builds a Bloom filter from a dump with a SHA-1 HASH:COUNT line per password, e.g. the Have I Been Pwned one",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			log := log.With(
+				zap.String("service", "breached-build"),
+				zap.String("dump", args[0]),
+			)
+
+			filter, err := BuildBreachedFilter(args[0], falsePositiveRate)
+			if err != nil {
+				log.With(zap.Error(err)).Error("failed to build breached passwords filter")
+				return
+			}
+
+			if err := WriteBreachedFilter(output, filter); err != nil {
+				log.With(zap.Error(err)).Error("failed to write breached passwords filter")
+				return
+			}
+			log.With(zap.String("output", output)).Info("breached passwords filter built")
+		},
+	}
+	breachedBuildCmd.Flags().StringVar(&output, "output", "breached.bloom", "path of the filter to set as USERS_PASSWORD_BREACHED_PASSWORDS_FILE")
+	breachedBuildCmd.Flags().Float64Var(&falsePositiveRate, "false-positive-rate", 0.001, "rate of passwords wrongly reported as breached")
+	breachedCmd.AddCommand(breachedBuildCmd)
+
+	rootCmd.AddCommand(runCmd, migrateCmd, revokeSessionsCmd, adminCmd, keysCmd, breachedCmd)
 	if err := rootCmd.Execute(); err != nil {
 		log.With(zap.String("cobra", "read")).
 			Error("failed to read command")
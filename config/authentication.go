@@ -1,33 +1,101 @@
 package config
 
 import (
+	"time"
+
 	"github.com/caarlos0/env"
-	"github.com/go-chi/jwtauth"
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/pkg/errors"
+
+	"github.com/anfimovoleh/ms-users/signing"
 )
 
 type Authentication struct {
-	VerifyKey string `env:"USERS_AUTHENTICATION_SECRET,required"`
+	// VerifyKey is the HMAC secret, it's only used if no private keys are configured
+	VerifyKey string `env:"USERS_AUTHENTICATION_SECRET"`
 	Algorithm string `env:"USERS_AUTHENTICATION_ALGORITHM" envDefault:"HS256"`
+	// PrivateKeyFiles are PEM encoded RSA, ECDSA or Ed25519 keys. The first
+	// one signs the tokens, the rest are only used to verify them.
+	PrivateKeyFiles []string `env:"USERS_AUTHENTICATION_PRIVATE_KEY_FILES" envSeparator:","`
+	// KeysDir is the keystore managed by the keys rotate command, it takes
+	// precedence over PrivateKeyFiles and is reloaded every KeysReloadInterval
+	KeysDir            string        `env:"USERS_AUTHENTICATION_KEYS_DIR"`
+	KeysReloadInterval time.Duration `env:"USERS_AUTHENTICATION_KEYS_RELOAD_INTERVAL" envDefault:"1m"`
+	AccessTokenTTL     time.Duration `env:"USERS_AUTHENTICATION_ACCESS_TOKEN_TTL" envDefault:"15m"`
+	RefreshTokenTTL    time.Duration `env:"USERS_AUTHENTICATION_REFRESH_TOKEN_TTL" envDefault:"720h"`
+	Issuer             string        `env:"USERS_AUTHENTICATION_ISSUER" envDefault:"ms-users"`
+	Audience           []string      `env:"USERS_AUTHENTICATION_AUDIENCE" envSeparator:"," envDefault:"ms-users"`
+}
+
+// Keystore returns nil if the keys are not managed by the keystore
+func (jwt *Authentication) Keystore() *signing.Keystore {
+	if jwt.KeysDir == "" {
+		return nil
+	}
+
+	return signing.NewKeystore(jwt.KeysDir)
+}
+
+func (jwt *Authentication) GetJWTEntry() (*signing.JWTAuth, error) {
+	if keystore := jwt.Keystore(); keystore != nil {
+		return keystore.Load()
+	}
+
+	if len(jwt.PrivateKeyFiles) == 0 {
+		key, err := signing.NewHMACKey(jwa.SignatureAlgorithm(jwt.Algorithm), []byte(jwt.VerifyKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid HMAC configuration")
+		}
+
+		return signing.New(key)
+	}
+
+	keys := make([]*signing.Key, 0, len(jwt.PrivateKeyFiles))
+	for _, path := range jwt.PrivateKeyFiles {
+		key, err := signing.LoadPrivateKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return signing.New(keys[0], keys[1:]...)
 }
 
-func (jwt *Authentication) GetJWTEntry() *jwtauth.JWTAuth {
-	return jwtauth.New(jwt.Algorithm, []byte(jwt.VerifyKey), nil)
+func (c *ConfigImpl) Authentication() *Authentication {
+	if c.auth != nil {
+		return c.auth
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	authentication := &Authentication{}
+	if err := env.Parse(authentication); err != nil {
+		panic(err)
+	}
+
+	c.auth = authentication
+
+	return c.auth
 }
 
-func (c *ConfigImpl) JWT() *jwtauth.JWTAuth {
+func (c *ConfigImpl) JWT() *signing.JWTAuth {
 	if c.jwt != nil {
 		return c.jwt
 	}
 
+	authentication := c.Authentication()
+
 	c.Lock()
 	defer c.Unlock()
 
-	jwt := &Authentication{}
-	if err := env.Parse(jwt); err != nil {
-		panic(err)
+	jwt, err := authentication.GetJWTEntry()
+	if err != nil {
+		panic(errors.Wrap(err, "failed to init jwt"))
 	}
 
-	c.jwt = jwt.GetJWTEntry()
+	c.jwt = jwt
 
 	return c.jwt
 }
@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -14,6 +16,15 @@ type HTTP struct {
 	Host            string        `env:"USERS_API_HOST,required"`
 	Port            string        `env:"USERS_API_PORT,required"`
 	ReqDurThreshold time.Duration `env:"USERS_HTTP_REQ_DUR_THRESHOLD" envDefault:"5s"`
+	// TrustedProxies are the IPs or CIDRs of the reverse proxies, the client IP
+	// is taken from X-Forwarded-For only for the requests coming from them
+	TrustedProxies []string `env:"USERS_HTTP_TRUSTED_PROXIES" envSeparator:","`
+
+	trustedProxies []*net.IPNet
+}
+
+func (h HTTP) TrustedProxyNetworks() []*net.IPNet {
+	return h.trustedProxies
 }
 
 func (h HTTP) URL() (*url.URL, error) {
@@ -35,6 +46,24 @@ func (c *ConfigImpl) HTTP() *HTTP {
 		panic(err)
 	}
 
+	for _, proxy := range http.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			if strings.Contains(proxy, ":") {
+				proxy += "/128"
+			} else {
+				proxy += "/32"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			panic(errors.Wrapf(err, "invalid trusted proxy %q", proxy))
+		}
+
+		http.trustedProxies = append(http.trustedProxies, network)
+	}
+
 	c.http = http
 
 	return c.http
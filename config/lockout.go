@@ -0,0 +1,79 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/pkg/errors"
+
+	"github.com/anfimovoleh/ms-users/lockout"
+)
+
+const (
+	LockoutStoreMemory   = "memory"
+	LockoutStorePostgres = "postgres"
+)
+
+// Lockout configures the protection of the login against password guessing,
+// failures are counted per account and per client IP
+type Lockout struct {
+	// Store is either memory, fitting a single replica, or postgres
+	Store            string        `env:"USERS_LOCKOUT_STORE" envDefault:"postgres"`
+	AccountThreshold int           `env:"USERS_LOCKOUT_ACCOUNT_THRESHOLD" envDefault:"5"`
+	IPThreshold      int           `env:"USERS_LOCKOUT_IP_THRESHOLD" envDefault:"50"`
+	Duration         time.Duration `env:"USERS_LOCKOUT_DURATION" envDefault:"15m"`
+	BaseDelay        time.Duration `env:"USERS_LOCKOUT_BASE_DELAY" envDefault:"1s"`
+	MaxDelay         time.Duration `env:"USERS_LOCKOUT_MAX_DELAY" envDefault:"1m"`
+	Window           time.Duration `env:"USERS_LOCKOUT_WINDOW" envDefault:"1h"`
+
+	accounts *lockout.Limiter
+	ips      *lockout.Limiter
+}
+
+func (l *Lockout) Accounts() *lockout.Limiter {
+	return l.accounts
+}
+
+func (l *Lockout) IPs() *lockout.Limiter {
+	return l.ips
+}
+
+func (l *Lockout) params(threshold int) lockout.Params {
+	return lockout.Params{
+		Threshold: threshold,
+		Duration:  l.Duration,
+		BaseDelay: l.BaseDelay,
+		MaxDelay:  l.MaxDelay,
+		Window:    l.Window,
+	}
+}
+
+func (c *ConfigImpl) Lockout() *Lockout {
+	if c.lockout != nil {
+		return c.lockout
+	}
+
+	lockoutConfig := &Lockout{}
+	if err := env.Parse(lockoutConfig); err != nil {
+		panic(err)
+	}
+
+	var store lockout.Store
+	switch lockoutConfig.Store {
+	case LockoutStoreMemory:
+		store = lockout.NewMemoryStore()
+	case LockoutStorePostgres:
+		store = lockout.NewPostgresStore(c.DB())
+	default:
+		panic(errors.Errorf("unknown lockout store %q", lockoutConfig.Store))
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	lockoutConfig.accounts = lockout.New(store, lockoutConfig.params(lockoutConfig.AccountThreshold))
+	lockoutConfig.ips = lockout.New(store, lockoutConfig.params(lockoutConfig.IPThreshold))
+	c.lockout = lockoutConfig
+
+	return c.lockout
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"github.com/caarlos0/env"
+)
+
+type Login struct {
+	RequireVerifiedEmail bool `env:"USERS_LOGIN_REQUIRE_VERIFIED_EMAIL" envDefault:"false"`
+}
+
+func (c *ConfigImpl) Login() *Login {
+	if c.login != nil {
+		return c.login
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	login := &Login{}
+	if err := env.Parse(login); err != nil {
+		panic(err)
+	}
+
+	c.login = login
+
+	return c.login
+}
@@ -8,8 +8,7 @@ import (
 
 	"github.com/anfimovoleh/ms-users/db"
 	"github.com/anfimovoleh/ms-users/email"
-
-	"github.com/go-chi/jwtauth"
+	"github.com/anfimovoleh/ms-users/signing"
 )
 
 type Config interface {
@@ -18,7 +17,16 @@ type Config interface {
 	EmailClient() *email.ClientImpl
 	WebsiteURL() *url.URL
 	DB() *db.DB
-	JWT() *jwtauth.JWTAuth
+	JWT() *signing.JWTAuth
+	Authentication() *Authentication
+	Login() *Login
+	Tokens() *Tokens
+	MFA() *MFA
+	WebAuthn() *WebAuthn
+	Password() *Password
+	Lockout() *Lockout
+	RateLimit() *RateLimit
+	SessionCookie() *SessionCookie
 }
 
 type ConfigImpl struct {
@@ -30,7 +38,17 @@ type ConfigImpl struct {
 	email  *email.ClientImpl
 	webApp *url.URL
 	db     *db.DB
-	jwt    *jwtauth.JWTAuth
+	jwt    *signing.JWTAuth
+	login  *Login
+	tokens *Tokens
+	auth   *Authentication
+	mfa    *MFA
+
+	webAuthn      *WebAuthn
+	password      *Password
+	lockout       *Lockout
+	rateLimit     *RateLimit
+	sessionCookie *SessionCookie
 }
 
 func New() Config {
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"encoding/base64"
+
+	"github.com/caarlos0/env"
+	"github.com/pkg/errors"
+
+	"github.com/anfimovoleh/ms-users/mfa"
+)
+
+type MFA struct {
+	// EncryptionKey is the base64 encoded AES-256 key the secrets are stored
+	// with, MFA is disabled if it is not set. Users who have already enabled
+	// it can't log in without the key.
+	EncryptionKey string `env:"USERS_MFA_ENCRYPTION_KEY"`
+	// Issuer is shown in the authenticator apps next to the account
+	Issuer string `env:"USERS_MFA_ISSUER" envDefault:"ms-users"`
+
+	cipher *mfa.Cipher
+}
+
+func (m *MFA) Cipher() *mfa.Cipher {
+	return m.cipher
+}
+
+func (m *MFA) Enabled() bool {
+	return m.cipher != nil
+}
+
+func (c *ConfigImpl) MFA() *MFA {
+	if c.mfa != nil {
+		return c.mfa
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	mfaConfig := &MFA{}
+	if err := env.Parse(mfaConfig); err != nil {
+		panic(err)
+	}
+
+	if mfaConfig.EncryptionKey == "" {
+		c.mfa = mfaConfig
+		return c.mfa
+	}
+
+	key, err := base64.StdEncoding.DecodeString(mfaConfig.EncryptionKey)
+	if err != nil {
+		panic(errors.Wrap(err, "invalid mfa encryption key"))
+	}
+
+	if len(key) != 32 {
+		panic(errors.New("mfa encryption key must be 32 bytes long"))
+	}
+
+	mfaConfig.cipher, err = mfa.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+
+	c.mfa = mfaConfig
+
+	return c.mfa
+}
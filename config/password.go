@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+
+	"github.com/caarlos0/env"
+	"github.com/pkg/errors"
+
+	"github.com/anfimovoleh/ms-users/password"
+)
+
+// Password configures the hashing of the user passwords and the policy new
+// passwords have to satisfy. Changing the hashing parameters makes the stored
+// hashes rehashed on the next login.
+type Password struct {
+	Argon2Time uint `env:"USERS_PASSWORD_ARGON2_TIME" envDefault:"3"`
+	// Argon2Memory is in KiB
+	Argon2Memory     uint `env:"USERS_PASSWORD_ARGON2_MEMORY" envDefault:"65536"`
+	Argon2Threads    uint `env:"USERS_PASSWORD_ARGON2_THREADS" envDefault:"2"`
+	Argon2SaltLength uint `env:"USERS_PASSWORD_ARGON2_SALT_LENGTH" envDefault:"16"`
+	Argon2KeyLength  uint `env:"USERS_PASSWORD_ARGON2_KEY_LENGTH" envDefault:"32"`
+
+	MinLength          int  `env:"USERS_PASSWORD_MIN_LENGTH" envDefault:"8"`
+	MaxLength          int  `env:"USERS_PASSWORD_MAX_LENGTH" envDefault:"128"`
+	RequireLowercase   bool `env:"USERS_PASSWORD_REQUIRE_LOWERCASE" envDefault:"false"`
+	RequireUppercase   bool `env:"USERS_PASSWORD_REQUIRE_UPPERCASE" envDefault:"false"`
+	RequireDigit       bool `env:"USERS_PASSWORD_REQUIRE_DIGIT" envDefault:"false"`
+	RequireSymbol      bool `env:"USERS_PASSWORD_REQUIRE_SYMBOL" envDefault:"false"`
+	RejectPersonalInfo bool `env:"USERS_PASSWORD_REJECT_PERSONAL_INFO" envDefault:"true"`
+	// PolicyFile is a JSON file with the policy rules, the rules it sets
+	// override the ones from the environment
+	PolicyFile string `env:"USERS_PASSWORD_POLICY_FILE"`
+	// CommonPasswordsFile lists the rejected passwords one per line,
+	// a short built-in list is used if it is not set
+	CommonPasswordsFile string `env:"USERS_PASSWORD_COMMON_PASSWORDS_FILE"`
+	// BreachedPasswordsFile is either a SHA-1 dump sorted by hash or a Bloom
+	// filter built from it with the breached build command
+	BreachedPasswordsFile string `env:"USERS_PASSWORD_BREACHED_PASSWORDS_FILE"`
+	// HistorySize is the amount of previous passwords a new one can't match
+	HistorySize int `env:"USERS_PASSWORD_HISTORY_SIZE" envDefault:"5"`
+
+	hasher password.Hasher
+	policy *password.Policy
+}
+
+func (p *Password) Hasher() password.Hasher {
+	return p.hasher
+}
+
+func (p *Password) Policy() *password.Policy {
+	return p.policy
+}
+
+// argon2Params checks the ranges of the RFC 9106 parameters, argon2.IDKey
+// panics on zero time or threads and the threads don't fit uint8 above 255
+func (p *Password) argon2Params() (password.Argon2Params, error) {
+	switch {
+	case p.Argon2Time < 1 || p.Argon2Time > math.MaxUint32:
+		return password.Argon2Params{}, errors.Errorf("argon2 time %d is out of range [1, %d]", p.Argon2Time, uint32(math.MaxUint32))
+	case p.Argon2Threads < 1 || p.Argon2Threads > math.MaxUint8:
+		return password.Argon2Params{}, errors.Errorf("argon2 threads %d is out of range [1, %d]", p.Argon2Threads, math.MaxUint8)
+	case p.Argon2Memory < 8*p.Argon2Threads || p.Argon2Memory > math.MaxUint32:
+		return password.Argon2Params{}, errors.Errorf("argon2 memory %d KiB is out of range [8*threads, %d]", p.Argon2Memory, uint32(math.MaxUint32))
+	case p.Argon2SaltLength < 8 || p.Argon2SaltLength > math.MaxUint32:
+		return password.Argon2Params{}, errors.Errorf("argon2 salt length %d is out of range [8, %d]", p.Argon2SaltLength, uint32(math.MaxUint32))
+	case p.Argon2KeyLength < 4 || p.Argon2KeyLength > math.MaxUint32:
+		return password.Argon2Params{}, errors.Errorf("argon2 key length %d is out of range [4, %d]", p.Argon2KeyLength, uint32(math.MaxUint32))
+	}
+
+	return password.Argon2Params{
+		Time:       uint32(p.Argon2Time),
+		Memory:     uint32(p.Argon2Memory),
+		Threads:    uint8(p.Argon2Threads),
+		SaltLength: uint32(p.Argon2SaltLength),
+		KeyLength:  uint32(p.Argon2KeyLength),
+	}, nil
+}
+
+func (p *Password) loadPolicy() (*password.Policy, error) {
+	policy := &password.Policy{
+		MinLength:          p.MinLength,
+		MaxLength:          p.MaxLength,
+		RequireLowercase:   p.RequireLowercase,
+		RequireUppercase:   p.RequireUppercase,
+		RequireDigit:       p.RequireDigit,
+		RequireSymbol:      p.RequireSymbol,
+		RejectPersonalInfo: p.RejectPersonalInfo,
+	}
+
+	if p.PolicyFile != "" {
+		raw, err := os.ReadFile(p.PolicyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read password policy file")
+		}
+
+		if err := json.Unmarshal(raw, policy); err != nil {
+			return nil, errors.Wrap(err, "failed to parse password policy file")
+		}
+	}
+
+	if p.CommonPasswordsFile == "" {
+		policy.SetCommonPasswords(password.DefaultCommonPasswords)
+	} else if err := policy.LoadCommonPasswords(p.CommonPasswordsFile); err != nil {
+		return nil, err
+	}
+
+	if p.BreachedPasswordsFile != "" {
+		corpus, err := password.OpenBreachedCorpus(p.BreachedPasswordsFile)
+		if err != nil {
+			return nil, err
+		}
+
+		policy.SetBreachedCorpus(corpus)
+	}
+
+	return policy, nil
+}
+
+func (c *ConfigImpl) Password() *Password {
+	if c.password != nil {
+		return c.password
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	passwordConfig := &Password{}
+	if err := env.Parse(passwordConfig); err != nil {
+		panic(err)
+	}
+
+	params, err := passwordConfig.argon2Params()
+	if err != nil {
+		panic(errors.Wrap(err, "invalid password hashing parameters"))
+	}
+
+	passwordConfig.hasher = password.NewArgon2id(params)
+
+	policy, err := passwordConfig.loadPolicy()
+	if err != nil {
+		panic(errors.Wrap(err, "invalid password policy"))
+	}
+
+	passwordConfig.policy = policy
+	c.password = passwordConfig
+
+	return c.password
+}
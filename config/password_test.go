@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+func TestArgon2Params(t *testing.T) {
+	valid := Password{
+		Argon2Time:       3,
+		Argon2Memory:     65536,
+		Argon2Threads:    2,
+		Argon2SaltLength: 16,
+		Argon2KeyLength:  32,
+	}
+
+	cases := []struct {
+		name   string
+		modify func(p *Password)
+		valid  bool
+	}{
+		{name: "defaults", modify: func(p *Password) {}, valid: true},
+		{name: "zero time", modify: func(p *Password) { p.Argon2Time = 0 }},
+		{name: "zero threads", modify: func(p *Password) { p.Argon2Threads = 0 }},
+		{name: "max threads", modify: func(p *Password) { p.Argon2Threads = 255 }, valid: true},
+		{name: "threads overflow", modify: func(p *Password) { p.Argon2Threads = 256 }},
+		{name: "memory below 8 KiB per thread", modify: func(p *Password) { p.Argon2Memory = 15 }},
+		{name: "short salt", modify: func(p *Password) { p.Argon2SaltLength = 4 }},
+		{name: "short key", modify: func(p *Password) { p.Argon2KeyLength = 0 }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := valid
+			c.modify(&p)
+
+			_, err := p.argon2Params()
+			if c.valid && err != nil {
+				t.Errorf("valid params rejected: %v", err)
+			}
+			if !c.valid && err == nil {
+				t.Error("invalid params accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/caarlos0/env"
+	"github.com/pkg/errors"
+
+	"github.com/anfimovoleh/ms-users/ratelimit"
+)
+
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+)
+
+// RouteLimits are the limits of a public route, nil means unlimited
+type RouteLimits struct {
+	IP    *ratelimit.Limit
+	Email *ratelimit.Limit
+}
+
+// RateLimit configures the limits of the public /user routes, the limits are
+// formatted as <requests>/<window>, e.g. 10/1h
+type RateLimit struct {
+	// Store is either memory, fitting a single replica, or postgres
+	Store string `env:"USERS_RATE_LIMIT_STORE" envDefault:"postgres"`
+	// IP limits every public route per client IP
+	IP ratelimit.Limit `env:"USERS_RATE_LIMIT_IP" envDefault:"60/1m"`
+	// Email limits the routes sending emails per email address
+	Email       ratelimit.Limit `env:"USERS_RATE_LIMIT_EMAIL" envDefault:"5/1h"`
+	EmailRoutes []string        `env:"USERS_RATE_LIMIT_EMAIL_ROUTES" envSeparator:"," envDefault:"signup,reset_password,login/magic,verify/resend,reset_password/resend"`
+	// Routes override the limits of single routes formatted as
+	// <route>:<ip|email>=<limit>, e.g. signup:ip=10/1h,reset_password:email=3/1h
+	Routes []string `env:"USERS_RATE_LIMIT_ROUTES" envSeparator:","`
+
+	limiter *ratelimit.Limiter
+	routes  map[string]RouteLimits
+}
+
+func (r *RateLimit) Limiter() *ratelimit.Limiter {
+	return r.limiter
+}
+
+// Route returns the limits of the route, the route is its path under /user
+func (r *RateLimit) Route(route string) RouteLimits {
+	if limits, ok := r.routes[route]; ok {
+		return limits
+	}
+
+	return RouteLimits{IP: &r.IP}
+}
+
+func (r *RateLimit) parseRoutes() error {
+	r.routes = make(map[string]RouteLimits)
+	for _, route := range r.EmailRoutes {
+		if route = strings.TrimSpace(route); route != "" {
+			r.routes[route] = RouteLimits{IP: &r.IP, Email: &r.Email}
+		}
+	}
+
+	for _, override := range r.Routes {
+		if override = strings.TrimSpace(override); override == "" {
+			continue
+		}
+
+		parts := strings.SplitN(override, "=", 2)
+		target := strings.SplitN(parts[0], ":", 2)
+		if len(parts) != 2 || len(target) != 2 {
+			return errors.Errorf("invalid route rate limit %q", override)
+		}
+
+		limit, err := ratelimit.ParseLimit(parts[1])
+		if err != nil {
+			return err
+		}
+
+		limits := r.Route(target[0])
+		switch target[1] {
+		case "ip":
+			limits.IP = &limit
+		case "email":
+			limits.Email = &limit
+		default:
+			return errors.Errorf("unknown rate limit key %q", target[1])
+		}
+		r.routes[target[0]] = limits
+	}
+
+	return nil
+}
+
+func (c *ConfigImpl) RateLimit() *RateLimit {
+	if c.rateLimit != nil {
+		return c.rateLimit
+	}
+
+	rateLimit := &RateLimit{}
+	err := env.ParseWithFuncs(rateLimit, env.CustomParsers{
+		reflect.TypeOf(ratelimit.Limit{}): func(value string) (interface{}, error) {
+			return ratelimit.ParseLimit(value)
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	if err := rateLimit.parseRoutes(); err != nil {
+		panic(errors.Wrap(err, "invalid rate limits"))
+	}
+
+	var store ratelimit.Store
+	switch rateLimit.Store {
+	case RateLimitStoreMemory:
+		store = ratelimit.NewMemoryStore()
+	case RateLimitStorePostgres:
+		store = ratelimit.NewPostgresStore(c.DB())
+	default:
+		panic(errors.Errorf("unknown rate limit store %q", rateLimit.Store))
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	rateLimit.limiter = ratelimit.New(store)
+	c.rateLimit = rateLimit
+
+	return c.rateLimit
+}
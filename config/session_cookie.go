@@ -0,0 +1,69 @@
+package config
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/caarlos0/env"
+	"github.com/pkg/errors"
+)
+
+// SessionCookie is the opt-in mode for browser clients, the tokens are set
+// as HttpOnly cookies instead of being returned in the response body and the
+// requests authenticated by them are protected by double-submit CSRF tokens.
+type SessionCookie struct {
+	Enabled bool `env:"USERS_SESSION_COOKIE_ENABLED" envDefault:"false"`
+	// AccessTokenName defaults to the cookie jwtauth.TokenFromCookie reads
+	AccessTokenName  string `env:"USERS_SESSION_COOKIE_ACCESS_TOKEN_NAME" envDefault:"jwt"`
+	RefreshTokenName string `env:"USERS_SESSION_COOKIE_REFRESH_TOKEN_NAME" envDefault:"refresh_token"`
+	// CSRFTokenName is the cookie readable by the web app, its value has to
+	// be sent back in the X-CSRF-Token header
+	CSRFTokenName string `env:"USERS_SESSION_COOKIE_CSRF_TOKEN_NAME" envDefault:"csrf_token"`
+	// Domain has to cover the host of the web app, e.g. example.com for the
+	// app at app.example.com and the API at api.example.com, otherwise the
+	// web app can't read the CSRF token cookie. CORS only allows the origin
+	// of the web app in the cookie mode.
+	Domain string `env:"USERS_SESSION_COOKIE_DOMAIN"`
+	Path   string `env:"USERS_SESSION_COOKIE_PATH" envDefault:"/"`
+	Secure bool   `env:"USERS_SESSION_COOKIE_SECURE" envDefault:"true"`
+	// SameSite is one of strict, lax or none, none requires Secure
+	SameSite string `env:"USERS_SESSION_COOKIE_SAME_SITE" envDefault:"lax"`
+
+	sameSite http.SameSite
+}
+
+func (s *SessionCookie) SameSiteMode() http.SameSite {
+	return s.sameSite
+}
+
+func (c *ConfigImpl) SessionCookie() *SessionCookie {
+	if c.sessionCookie != nil {
+		return c.sessionCookie
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	sessionCookie := &SessionCookie{}
+	if err := env.Parse(sessionCookie); err != nil {
+		panic(err)
+	}
+
+	switch strings.ToLower(sessionCookie.SameSite) {
+	case "strict":
+		sessionCookie.sameSite = http.SameSiteStrictMode
+	case "lax":
+		sessionCookie.sameSite = http.SameSiteLaxMode
+	case "none":
+		if !sessionCookie.Secure {
+			panic(errors.New("SameSite=None session cookies have to be secure"))
+		}
+		sessionCookie.sameSite = http.SameSiteNoneMode
+	default:
+		panic(errors.Errorf("unknown session cookie SameSite mode %q", sessionCookie.SameSite))
+	}
+
+	c.sessionCookie = sessionCookie
+
+	return c.sessionCookie
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+// Tokens holds the lifetime of the one-time tokens for every purpose
+type Tokens struct {
+	VerifyEmailTTL   time.Duration `env:"USERS_TOKEN_VERIFY_EMAIL_TTL" envDefault:"72h"`
+	ResetPasswordTTL time.Duration `env:"USERS_TOKEN_RESET_PASSWORD_TTL" envDefault:"1h"`
+	ChangeEmailTTL   time.Duration `env:"USERS_TOKEN_CHANGE_EMAIL_TTL" envDefault:"24h"`
+	MagicLinkTTL     time.Duration `env:"USERS_TOKEN_MAGIC_LINK_TTL" envDefault:"15m"`
+	MFAChallengeTTL  time.Duration `env:"USERS_TOKEN_MFA_CHALLENGE_TTL" envDefault:"5m"`
+	RevertEmailTTL   time.Duration `env:"USERS_TOKEN_REVERT_EMAIL_TTL" envDefault:"168h"`
+	RevokeSessionTTL time.Duration `env:"USERS_TOKEN_REVOKE_SESSION_TTL" envDefault:"168h"`
+	// ResendCooldown is the minimal time between two emails with the same token
+	ResendCooldown time.Duration `env:"USERS_TOKEN_RESEND_COOLDOWN" envDefault:"1m"`
+}
+
+func (t Tokens) TTL(purpose db.TokenPurpose) time.Duration {
+	switch purpose {
+	case db.TokenPurposeVerifyEmail:
+		return t.VerifyEmailTTL
+	case db.TokenPurposeResetPassword:
+		return t.ResetPasswordTTL
+	case db.TokenPurposeChangeEmail:
+		return t.ChangeEmailTTL
+	case db.TokenPurposeMagicLink:
+		return t.MagicLinkTTL
+	case db.TokenPurposeMFAChallenge:
+		return t.MFAChallengeTTL
+	case db.TokenPurposeRevertEmail:
+		return t.RevertEmailTTL
+	case db.TokenPurposeRevokeSession:
+		return t.RevokeSessionTTL
+	default:
+		return 0
+	}
+}
+
+func (c *ConfigImpl) Tokens() *Tokens {
+	if c.tokens != nil {
+		return c.tokens
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	tokens := &Tokens{}
+	if err := env.Parse(tokens); err != nil {
+		panic(err)
+	}
+
+	c.tokens = tokens
+
+	return c.tokens
+}
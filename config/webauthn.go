@@ -0,0 +1,86 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/pkg/errors"
+)
+
+// WebAuthn configures passkeys, the relying party is the website the users
+// sign in to, so its ID and origin are derived from the website URL
+type WebAuthn struct {
+	RPDisplayName string        `env:"USERS_WEBAUTHN_RP_DISPLAY_NAME" envDefault:"ms-users"`
+	Timeout       time.Duration `env:"USERS_WEBAUTHN_TIMEOUT" envDefault:"1m"`
+	// SessionTTL limits the time between the begin and finish requests of a ceremony
+	SessionTTL time.Duration `env:"USERS_WEBAUTHN_SESSION_TTL" envDefault:"5m"`
+	// DummyCredentialSecret derives the passkey IDs offered for unknown
+	// emails, it has to be shared by the replicas for the IDs to be stable.
+	// A random one is generated if it is not set.
+	DummyCredentialSecret string `env:"USERS_WEBAUTHN_DUMMY_CREDENTIAL_SECRET"`
+
+	relyingParty *webauthn.WebAuthn
+	dummySecret  []byte
+}
+
+func (w *WebAuthn) RelyingParty() *webauthn.WebAuthn {
+	return w.relyingParty
+}
+
+// DummyCredentialID returns the same made up passkey ID for the email every
+// time, so the login options of an unknown email look like a real user's
+func (w *WebAuthn) DummyCredentialID(email string) []byte {
+	mac := hmac.New(sha256.New, w.dummySecret)
+	mac.Write([]byte(strings.ToLower(email)))
+	return mac.Sum(nil)
+}
+
+func (c *ConfigImpl) WebAuthn() *WebAuthn {
+	if c.webAuthn != nil {
+		return c.webAuthn
+	}
+
+	website := c.WebsiteURL()
+
+	c.Lock()
+	defer c.Unlock()
+
+	webAuthnConfig := &WebAuthn{}
+	if err := env.Parse(webAuthnConfig); err != nil {
+		panic(err)
+	}
+
+	relyingParty, err := webauthn.New(&webauthn.Config{
+		RPDisplayName: webAuthnConfig.RPDisplayName,
+		RPID:          website.Hostname(),
+		RPOrigin:      website.Scheme + "://" + website.Host,
+		Timeout:       int(webAuthnConfig.Timeout / time.Millisecond),
+		//a passkey replaces both the password and the second factor,
+		//so the authenticator has to verify the user itself
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			UserVerification: protocol.VerificationRequired,
+		},
+	})
+	if err != nil {
+		panic(errors.Wrap(err, "invalid webauthn relying party"))
+	}
+
+	webAuthnConfig.relyingParty = relyingParty
+
+	webAuthnConfig.dummySecret = []byte(webAuthnConfig.DummyCredentialSecret)
+	if len(webAuthnConfig.dummySecret) == 0 {
+		webAuthnConfig.dummySecret = make([]byte, sha256.Size)
+		if _, err := rand.Read(webAuthnConfig.dummySecret); err != nil {
+			panic(errors.Wrap(err, "failed to generate webauthn dummy credential secret"))
+		}
+	}
+	c.webAuthn = webAuthnConfig
+
+	return c.webAuthn
+}
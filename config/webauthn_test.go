@@ -0,0 +1,23 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDummyCredentialID(t *testing.T) {
+	webAuthn := &WebAuthn{dummySecret: []byte("secret")}
+
+	id := webAuthn.DummyCredentialID("user@example.com")
+	if !bytes.Equal(id, webAuthn.DummyCredentialID("User@Example.com")) {
+		t.Error("dummy credential id depends on the email case")
+	}
+	if bytes.Equal(id, webAuthn.DummyCredentialID("other@example.com")) {
+		t.Error("dummy credential ids of different emails match")
+	}
+
+	other := &WebAuthn{dummySecret: []byte("other secret")}
+	if bytes.Equal(id, other.DummyCredentialID("user@example.com")) {
+		t.Error("dummy credential ids of different secrets match")
+	}
+}
@@ -2,6 +2,22 @@
 // sources:
 // migrations/001_users.sql
 // migrations/002_tokens.sql
+// migrations/003_users_email_verified.sql
+// migrations/004_tokens_purpose.sql
+// migrations/005_refresh_tokens.sql
+// migrations/006_token_revocation.sql
+// migrations/007_mfa_totp.sql
+// migrations/008_mfa_recovery_codes.sql
+// migrations/009_webauthn.sql
+// migrations/010_password_history.sql
+// migrations/011_login_attempts.sql
+// migrations/012_rate_limits.sql
+// migrations/013_tokens_payload.sql
+// migrations/014_sessions.sql
+// migrations/015_known_devices.sql
+// migrations/016_login_history.sql
+// migrations/017_users_is_admin.sql
+// migrations/018_login_attempts_last_failure_at.sql
 // DO NOT EDIT!
 
 package db
@@ -109,6 +125,326 @@ func migrations002_tokensSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations003_users_email_verifiedSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd2\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\xe2\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x2d\x4e\x2d\x2a\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x48\xcd\x4d\xcc\xcc\x89\x2f\x4b\x2d\xca\x4c\xcb\x4c\x4d\x89\x4f\x2c\x51\x28\xc9\xcc\x4d\x2d\x2e\x49\xcc\x2d\x50\x28\xcf\x2c\xc9\xc8\x2f\x85\x88\x28\x54\xe5\xe7\xa5\x5a\x73\x71\x21\x1b\xee\x92\x5f\x9e\x87\xcd\x78\x97\x20\xff\x00\x9c\xe6\x5b\x03\x06\x00\x3d\xc9\x53\xff\x9f\x00\x00\x00")
+
+func migrations003_users_email_verifiedSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations003_users_email_verifiedSql,
+		"migrations/003_users_email_verified.sql",
+	)
+}
+
+func migrations003_users_email_verifiedSql() (*asset, error) {
+	bytes, err := migrations003_users_email_verifiedSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/003_users_email_verified.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations004_tokens_purposeSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x91\x41\x6b\x83\x40\x10\x85\xef\xfb\x2b\xde\x2d\x09\xd5\x4b\x7b\xcc\xc9\x46\x0b\x05\xab\x25\x28\xf4\x26\x1b\x9d\x24\x4b\xe3\x8e\xec\xae\x35\xed\xaf\x2f\x6b\x0c\x24\x29\xa1\xb7\xe5\xcd\xbc\xf7\xbe\x61\xc3\x10\x0f\xad\xda\x19\xe9\x08\x65\x27\x44\x18\xc2\xf1\x27\x69\x8b\xda\x90\x74\xd4\x60\x43\x5b\x36\x84\xae\x37\x1d\x5b\xb2\xa0\xa3\xb2\x5e\xaf\xa5\xd6\xec\xb0\x21\x48\xe7\x8c\xda\xf4\x5e\x74\x0c\x89\xed\x81\x87\xc0\x27\x59\x86\xdb\xd3\x37\xa4\x21\xc8\xa6\xa1\x06\xd2\xfb\x3b\x65\xfc\x53\x8f\x19\xd0\x8c\x03\xeb\x1d\x19\x1f\x55\xb3\xb6\x7d\x4b\x8d\x88\xd2\x22\x59\xa3\x88\x9e\xd3\x64\x02\x12\x40\x14\xc7\x58\xe5\x69\xf9\x96\x9d\x71\xf0\x25\x4d\xbd\x97\x66\xfe\xf4\xb8\x40\x96\x17\xc8\xca\x34\x45\x9c\xbc\x44\x65\x5a\x60\x36\x0b\xae\x5d\xa7\x6e\x5b\x49\x07\xa7\x5a\xb2\x4e\xb6\x1d\x06\xe5\xf6\xdc\x9f\x14\xfc\xb0\xa6\xbf\x41\x9a\x87\xf9\x62\x29\xee\x50\x8d\xe2\x0d\x57\xbc\xce\xdf\xcf\xf6\xe0\x76\xe9\x02\xe3\x72\x6f\x29\xc4\x6a\x9d\x44\x45\x82\xd7\x2c\x4e\x3e\xa6\x86\xaa\xb7\x64\x2a\xd5\x54\x53\x74\xa5\x9a\x23\xf2\x6c\x9a\xce\xa7\x69\x70\x6e\xf6\x98\x97\x9f\x1a\xf3\xa0\x85\x18\x6b\xfe\x4b\xbd\x77\xe0\x68\xbe\xbe\x2f\xb8\x91\xe9\xd8\x29\x43\xb6\x92\x6e\xf9\x3b\x00\xd0\x1d\x25\xd0\x52\x02\x00\x00")
+
+func migrations004_tokens_purposeSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations004_tokens_purposeSql,
+		"migrations/004_tokens_purpose.sql",
+	)
+}
+
+func migrations004_tokens_purposeSql() (*asset, error) {
+	bytes, err := migrations004_tokens_purposeSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/004_tokens_purpose.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations005_refresh_tokensSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x91\x3d\x4f\xc3\x30\x10\x86\x77\xff\x8a\x1b\x13\x41\x37\xc4\xd2\x29\x34\x57\x14\x11\x9c\xca\xa4\x12\x9d\x22\xd3\x5c\x9b\x53\x49\x1c\xd9\xee\x07\xfc\x7a\x64\x40\x11\x8d\x18\xda\xf5\xde\xe7\x6c\xdd\xf3\x4e\x26\x70\xd3\xf2\xd6\x6a\x4f\xb0\xec\x85\x98\x29\x4c\x4a\x84\x32\x79\xc8\x11\x2c\x6d\x2c\xb9\xa6\xf2\x66\x47\x9d\x8b\x04\x00\xd7\x70\xd0\x76\xdd\x68\x1b\xdd\xdf\xc5\xb0\x50\xd9\x73\xa2\x56\xf0\x84\xab\x5b\x01\xb0\xd1\x2d\xbf\x7f\x54\x23\x48\x16\x25\xc8\x65\x9e\x07\x62\xef\xc8\x86\xfc\x8d\xb7\xdc\xf9\xb3\x68\x6d\x49\x7b\xaa\x2b\xed\xc1\x73\x4b\xce\xeb\xb6\x87\x23\xfb\xc6\xec\x7f\x26\xf0\x69\x3a\x3a\x5b\xa1\x53\xcf\x96\xdc\x35\x2b\xd6\xf8\x0b\x7e\xf9\x26\xe9\x60\x76\x17\x91\xf3\x42\x61\xf6\x28\x83\x05\x88\x7e\x2f\x8c\x41\xe1\x1c\x15\xca\x19\xbe\x40\x98\xb9\x88\xeb\x58\xc4\xd3\x41\x71\x26\x53\x7c\x1d\x29\xae\x06\x83\x15\xd7\x27\x28\xe4\xb8\x82\x21\x0f\x0f\xfd\xed\x2e\x35\xc7\x4e\x88\x54\x15\x8b\x7f\xbb\x9b\x7e\x0d\x00\x3b\x9a\xf8\xd0\xe8\x01\x00\x00")
+
+func migrations005_refresh_tokensSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations005_refresh_tokensSql,
+		"migrations/005_refresh_tokens.sql",
+	)
+}
+
+func migrations005_refresh_tokensSql() (*asset, error) {
+	bytes, err := migrations005_refresh_tokensSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/005_refresh_tokens.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations006_token_revocationSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\x31\x4f\xc3\x30\x10\x46\x77\xff\x8a\x6f\x4c\x04\x95\x18\x10\x4b\x26\xd3\x5c\x51\x85\x9b\x54\x26\x19\x3a\x45\x86\x9e\x52\x53\xc5\x8e\x6c\xb7\x45\xfc\x7a\xd4\xd0\x01\x50\x59\xed\xbb\xf7\x74\x6f\x36\xc3\xcd\x60\xfb\x60\x12\xa3\x1d\x85\x90\xaa\x21\x8d\x46\x3e\x2a\xc2\x21\x72\x88\x90\x65\x89\x79\xad\xda\x55\x85\xe4\xf7\xec\xba\x9e\x1d\x07\x93\xac\x77\x78\xb5\xbd\x75\x09\x55\xdd\xa0\x6a\x95\x42\x49\x0b\xd9\xaa\x06\x77\x85\x10\x73\x4d\xb2\xa1\x0b\x2a\xf0\xd1\xef\x79\xdb\x4d\x84\x98\x09\xe0\x3d\x59\x1c\x4d\x78\xdb\x99\x90\x3d\xdc\xe7\x58\xeb\xe5\x4a\xea\x0d\x9e\x69\x73\x2b\x30\xb9\x3b\xbb\xfd\x6b\x38\x7f\xf1\xc7\x68\x03\xc7\xce\x24\x24\x3b\x70\x4c\x66\x18\x71\xb2\x69\xe7\x0f\xdf\x2f\xf8\xf4\x8e\x7f\xad\x2c\x6a\x4d\xcb\xa7\xea\x0c\x47\x76\x41\xe7\xd0\xb4\x20\x4d\xd5\x9c\x5e\x26\x5d\xcc\xec\x36\x17\x79\x21\xc4\xcf\x28\xa5\x3f\x39\x21\x4a\x5d\xaf\xaf\x9e\x52\x5c\x4b\x36\x4d\xff\xd3\xac\xf8\x1a\x00\xac\x65\x7c\x2c\x72\x01\x00\x00")
+
+func migrations006_token_revocationSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations006_token_revocationSql,
+		"migrations/006_token_revocation.sql",
+	)
+}
+
+func migrations006_token_revocationSql() (*asset, error) {
+	bytes, err := migrations006_token_revocationSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/006_token_revocation.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations007_mfa_totpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x90\x3f\x4f\xc3\x30\x10\xc5\x77\x7f\x8a\x37\x36\x82\x4a\xec\x9d\x42\x73\x41\x15\x21\xa9\x4c\x32\x74\xb2\xdc\xe4\x5a\x2c\xe1\x38\xb2\x2f\xaa\xe0\xd3\xa3\x00\x95\x80\x85\xf5\xee\xe9\xfd\xf9\xad\xd7\xb8\xf1\xee\x1c\xad\x30\xba\x49\xa9\xad\xa6\xbc\x25\xb4\xf9\x7d\x45\xf0\x27\x6b\x24\xc8\xb4\x52\xc0\x9c\x38\x1a\x37\xe0\xe8\xce\x6e\x14\xec\xf5\xee\x29\xd7\x07\x3c\xd2\xe1\x56\x01\x89\xfb\xc8\x82\xe3\x9b\xb0\x45\xdd\xb4\xa8\xbb\xaa\x5a\x1e\x7d\x18\x4f\x2e\x7a\x1e\x8c\x15\x88\xf3\x9c\xc4\xfa\x09\x17\x27\x2f\x61\xfe\xba\xe0\x3d\x8c\xbc\x68\x5f\x6d\x12\x33\x27\x1e\x4c\x12\x9e\xae\x49\x57\x37\x14\x54\xe6\x5d\xd5\xe2\x6e\xd1\xf6\x91\xad\xfc\xeb\xfa\xab\x4a\xd9\x68\xda\x3d\xd4\x4b\x65\xac\xbe\xe7\x64\xd0\x54\x92\xa6\x7a\x4b\xcf\x9f\x13\xd3\xca\x0d\x99\xca\x36\x4a\xfd\x24\x53\x84\xcb\xa8\x54\xa1\x9b\xfd\x1f\x32\x9b\x8f\x01\x00\xe1\xc8\x10\x49\x40\x01\x00\x00")
+
+func migrations007_mfa_totpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations007_mfa_totpSql,
+		"migrations/007_mfa_totp.sql",
+	)
+}
+
+func migrations007_mfa_totpSql() (*asset, error) {
+	bytes, err := migrations007_mfa_totpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/007_mfa_totp.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations008_mfa_recovery_codesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x90\x41\x6b\xc2\x30\x18\x86\xef\xf9\x15\xef\xb1\x65\xf3\x32\xf0\xd4\x53\xb5\x9f\x52\xd6\xa5\x12\x2b\xcc\x53\xc8\x9a\xcc\xe6\xd0\x56\x92\x54\xb7\xfd\xfa\x51\xe7\x64\x82\xc3\x6b\xf2\xf2\x7c\x0f\xcf\x64\x82\x87\xd6\xee\x9c\x0a\x06\x9b\x3d\x63\x73\x41\x69\x45\xa8\xd2\x59\x41\x68\xdf\x95\x74\xa6\xee\x0f\xc6\x7d\xca\xba\xd7\xc6\x47\x0c\xb0\x1a\xb3\x7c\xb9\x26\x91\xa7\x05\x78\x59\x81\x6f\x8a\x02\x2b\x91\xbf\xa4\x62\x8b\x67\xda\x3e\x32\x60\xf0\xc6\x49\xab\xf1\x66\x77\xb6\x0b\x97\xd9\xf8\x35\x82\x64\xa3\x7c\x83\x83\x72\x75\xa3\x5c\xf4\x34\x9d\xc6\x57\x93\xc1\x1b\x2d\x55\x40\xb0\xad\xf1\x41\xb5\x7b\x1c\x6d\x68\xfa\xe1\xe7\x05\x5f\x7d\x67\x4e\x24\x67\x54\xb8\xbb\xbc\x22\x2f\x4a\x41\xf9\x92\x8f\x9a\x88\xce\x92\x31\x04\x2d\x48\x10\x9f\xd3\xfa\x24\xee\x23\xab\x63\x16\x27\x97\x1c\x39\xcf\xe8\xf5\x46\x0e\x79\x26\x48\xab\x3f\x50\xf2\x5b\xc1\x7e\x6f\x24\x8c\xfd\x6d\x9d\xf5\xc7\x8e\xb1\x4c\x94\xab\x7f\x5b\x27\xdf\x03\x00\xeb\x64\x0a\xe5\x9c\x01\x00\x00")
+
+func migrations008_mfa_recovery_codesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations008_mfa_recovery_codesSql,
+		"migrations/008_mfa_recovery_codes.sql",
+	)
+}
+
+func migrations008_mfa_recovery_codesSql() (*asset, error) {
+	bytes, err := migrations008_mfa_recovery_codesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/008_mfa_recovery_codes.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations009_webauthnSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x92\x4f\x6f\xb2\x40\x10\xc6\xef\x7c\x8a\x39\x42\xde\xd7\xa4\xff\xe2\x85\x13\x95\xb5\x31\xa5\x60\x28\x26\xf5\xb4\x59\x61\x82\x9b\xea\x42\x76\x67\xab\xf6\xd3\x37\xdb\xaa\x15\xab\x69\xec\x95\x79\xe6\x19\xf2\xfb\x6d\xaf\x07\xff\x96\xb2\xd6\x82\x10\x26\xad\xe7\x0d\x72\x16\x15\x0c\x8a\xe8\x3e\x61\xb0\xc2\x99\xb0\x34\x57\xbc\xd4\x58\xa1\x22\x29\x16\xc6\xf7\x00\x64\x05\xb3\x0d\xa1\x80\x34\x2b\x20\x9d\x24\x09\x8c\xf3\xd1\x53\x94\x4f\xe1\x91\x4d\xff\x7b\x00\xd6\xa0\xe6\x2e\x25\x6b\xa9\x68\x1f\x73\xa3\xd6\xce\x16\xb2\xe4\xaf\xb8\x39\xea\x70\x43\x41\x84\x86\x04\xc9\x46\x71\xda\xb4\x08\x6f\x42\x97\x73\xa1\xfd\xdb\x9b\xa0\x1b\x14\xb5\xfd\xf1\x17\xae\xc1\xc8\x5a\xf1\xb2\xb1\x8a\x8e\x8f\x43\xcc\x86\xd1\x24\x29\xe0\xca\xe5\x4a\x8d\x82\xb0\xe2\x82\x80\xe4\xd2\x1d\x5d\xb6\xb0\x92\x34\x6f\xec\xd7\x17\x78\x6f\x14\x76\xaa\x17\xc2\x10\xb7\xe6\xd7\x25\x57\x3f\xcc\x72\x36\x7a\x48\x1d\x0f\xf0\xb7\x34\x02\xc8\xd9\x90\xe5\x2c\x1d\xb0\xe7\x4f\x42\xc6\x97\x55\xe0\x05\xe1\x9e\xfa\x28\x8d\xd9\xcb\x49\xea\x7c\xdb\xc1\x65\xb5\x86\x2c\x3d\x6d\x66\x77\x27\x3c\xa7\xd1\xa0\x31\xb2\x51\x3b\x87\x3b\xb8\xfd\xbb\xe0\x6f\x26\x75\xdb\x98\x6f\x47\xd7\xfd\xae\xa3\x4a\x90\x00\xc2\x75\x77\x0b\xd7\xad\xd4\x68\x2e\x01\x7f\x21\xcc\xc3\x27\x1d\x37\x2b\xe5\x79\x71\x9e\x8d\xcf\xb1\x08\x4f\x4e\x0f\xb0\x86\x1f\x03\x00\x83\x4d\x50\x82\x23\x03\x00\x00")
+
+func migrations009_webauthnSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations009_webauthnSql,
+		"migrations/009_webauthn.sql",
+	)
+}
+
+func migrations009_webauthnSql() (*asset, error) {
+	bytes, err := migrations009_webauthnSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/009_webauthn.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations010_password_historySql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x50\x3d\x6f\xc2\x30\x10\xdd\xfd\x2b\xde\x98\xa8\x65\xa9\xc4\x94\x29\x90\x03\x45\x4d\x1d\x64\x82\x54\xa6\xc8\xc5\x16\xb9\x21\x1f\xb2\x4d\xd3\xf6\xd7\x57\xb4\x34\x2a\x42\xac\xf7\xbe\xee\xbd\xd9\x0c\x0f\x2d\x1f\x9d\x0e\x16\xbb\x41\x88\xa5\xa2\xb4\x22\x54\xe9\xa2\x20\x0c\xda\xfb\xb1\x77\xa6\x6e\xd8\x87\xde\x7d\x46\x02\x60\x83\x45\xbe\xde\x92\xca\xd3\x02\xb2\xac\x20\x77\x45\x81\x8d\xca\x5f\x52\xb5\xc7\x33\xed\x1f\x05\x70\xf2\xd6\xd5\x6c\xf0\xc6\x47\xee\xc2\x44\x3b\x43\x7f\x9e\x78\xd7\xee\xd0\x68\x17\x3d\xcd\xe7\xf1\x15\xe3\xe0\xac\x0e\xd6\xd4\x3a\x20\x70\x6b\x7d\xd0\xed\x80\x91\x43\xd3\x9f\x7e\x2f\xf8\xea\x3b\x7b\x25\x59\x95\x8a\xf2\xb5\x3c\xc7\x23\xba\x84\xc7\x50\xb4\x22\x45\x72\x49\xdb\x9f\x87\x7c\xc4\x26\x16\x71\x32\x95\xcc\x65\x46\xaf\x37\x25\xeb\x8b\xbe\x66\xf3\x81\x52\xde\xe0\x93\x7f\x22\xc4\xff\xf5\xb2\x7e\xec\x84\xc8\x54\xb9\xb9\xb3\x5e\xf2\x3d\x00\x3b\x59\x2a\x67\x6c\x01\x00\x00")
+
+func migrations010_password_historySqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations010_password_historySql,
+		"migrations/010_password_history.sql",
+	)
+}
+
+func migrations010_password_historySql() (*asset, error) {
+	bytes, err := migrations010_password_historySqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/010_password_history.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations011_login_attemptsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8f\x4d\x4b\xc3\x40\x14\x45\xf7\xef\x57\xdc\x65\x8b\x16\x44\xe8\xaa\xab\x68\x46\x10\x63\x5b\x42\xb2\xe8\x6a\x78\xd4\x67\x3a\x74\x3e\xc2\xcc\x8b\x45\x7f\xbd\xf8\x81\xba\x90\x6e\x2f\xe7\x5c\x38\x8b\x05\x2e\x82\x1b\x32\xab\xa0\x1f\x89\x6e\x5b\x53\x75\x06\x5d\x75\xd3\x18\xf8\x34\xb8\x68\x59\x55\xc2\xa8\x65\x46\xc0\x51\x5e\xf1\xc2\x79\x7f\xe0\x3c\xbb\x5e\x2e\xe7\x58\x6f\x3a\xac\xfb\xa6\xc1\xb6\xbd\x7f\xac\xda\x1d\x1e\xcc\xee\x92\x80\x67\x76\x7e\xca\x52\xe0\xa2\xca\x20\xf9\x17\xac\xcd\x5d\xd5\x37\x1d\xae\x3e\x30\xcf\x45\xed\x37\x6b\x59\xa1\x2e\x48\x51\x0e\x23\x4e\x4e\x0f\x69\xfa\x5a\xf0\x96\xa2\xfc\x3c\x7c\x7a\x69\x7f\x94\x27\x3b\x45\x75\xfe\x9c\x44\xf3\x15\xd1\xdf\xc6\x3a\x9d\x22\x51\xdd\x6e\xb6\xff\x36\xae\xde\x07\x00\x89\x46\x99\x2b\x10\x01\x00\x00")
+
+func migrations011_login_attemptsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations011_login_attemptsSql,
+		"migrations/011_login_attempts.sql",
+	)
+}
+
+func migrations011_login_attemptsSql() (*asset, error) {
+	bytes, err := migrations011_login_attemptsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/011_login_attempts.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations012_rate_limitsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x90\x41\x4b\xc3\x40\x10\x85\xef\xfb\x2b\xde\x31\xc1\x16\x44\xe8\xa9\xa7\x68\x56\x28\xc6\xa4\x84\x04\xec\x69\x59\xec\xd0\x0c\x35\xd9\xb0\x3b\xba\xad\xbf\x5e\x82\xd0\x36\x78\xf1\xfa\x1e\xfb\xed\xbc\x6f\xb9\xc4\x5d\xcf\x07\x6f\x85\xd0\x8e\x4a\x3d\xd5\x3a\x6b\x34\x9a\xec\xb1\xd0\x98\x52\xf3\xc1\x3d\x4b\x48\x14\x70\xa4\x33\xbe\xac\x7f\xef\xac\x4f\x1e\x56\xab\x14\x65\xd5\xa0\x6c\x8b\x62\xa1\x80\xc8\xc3\xde\x45\x13\xc4\x7a\x81\x70\x4f\x41\x6c\x3f\x22\xb2\x74\xee\xf3\x37\xc1\xb7\x1b\x68\xf6\xa8\x63\x09\xe0\x41\xe8\x40\xfe\x52\x20\xd7\xcf\x59\x5b\x34\xb8\x9f\xb8\x74\x1a\xd9\x53\x30\xf6\xff\xd4\x6d\xbd\x79\xcd\xea\x1d\x5e\xf4\x0e\xc9\x91\xce\x8b\xd9\x71\xa9\x4a\xd7\x97\x9d\x9b\x32\xd7\x6f\xb7\x3b\xcd\xf5\x3f\xc3\xfb\x13\xaa\x72\x66\xe1\xda\x4e\x90\x5b\x79\xb9\x8b\x83\x52\x79\x5d\x6d\xff\xca\x5b\xff\x0c\x00\x88\x11\xa0\x28\x66\x01\x00\x00")
+
+func migrations012_rate_limitsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations012_rate_limitsSql,
+		"migrations/012_rate_limits.sql",
+	)
+}
+
+func migrations012_rate_limitsSql() (*asset, error) {
+	bytes, err := migrations012_rate_limitsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/012_rate_limits.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations013_tokens_payloadSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\xce\xbd\x4a\xc0\x30\x14\xc5\xf1\x3d\x4f\x71\xb6\x2a\x9a\x0e\xa2\x53\xa7\x68\xea\x14\x5b\x29\xc9\x03\x5c\x9a\xdb\x0f\x6c\x93\x92\x04\x8b\x6f\x2f\x74\x10\x11\xe7\x3f\xfc\xce\x91\x12\x77\xfb\x3a\x27\x2a\x0c\x77\x08\x21\x25\x0e\xfa\xda\x22\x79\x8c\x94\xd2\xca\x19\x65\x61\x78\x2a\x84\x38\x81\x30\x6d\xf1\xbc\x07\xd7\x73\x7d\x85\xc0\x27\xc8\xfb\xc4\x39\x5f\x3d\x80\x77\x5a\x37\x8c\x0b\x85\x99\x85\x32\xb6\x1d\x60\xd5\xb3\x69\x51\xe2\x07\x87\x0c\xa5\x35\x5e\x7a\xe3\xde\xba\x9f\xa5\x4f\x4a\xe3\x42\xe9\xe6\xe1\xe9\xf1\x16\x5d\x6f\xd1\x39\x63\xa0\xdb\x57\xe5\x8c\x45\x55\x35\x42\xfc\xfe\xa9\xe3\x19\xc4\x7f\xb4\x1e\xfa\xf7\x3f\x76\xf3\x3d\x00\xc0\xe4\x17\x73\xe1\x00\x00\x00")
+
+func migrations013_tokens_payloadSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations013_tokens_payloadSql,
+		"migrations/013_tokens_payload.sql",
+	)
+}
+
+func migrations013_tokens_payloadSql() (*asset, error) {
+	bytes, err := migrations013_tokens_payloadSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/013_tokens_payload.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations014_sessionsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x91\x41\x4b\xf3\x40\x10\x86\xef\xfb\x2b\xe6\xd6\x84\xef\xeb\x41\x69\xbd\xf4\x14\x9b\xa9\x04\xe3\xa6\xac\x09\xd8\x53\x58\x9b\x21\x1d\x34\x9b\xb0\xbb\x6d\xc5\x5f\x2f\xd1\xba\x16\x41\xc4\xeb\xee\xf3\xbe\x03\xcf\x3b\x9d\xc2\xbf\x8e\x5b\xab\x3d\x41\x35\x08\xb1\x54\x98\x94\x08\x65\x72\x9d\x23\x38\x72\x8e\x7b\xe3\x22\x01\xc0\x0d\x1c\xb4\xdd\xee\xb4\x8d\xae\x66\x31\xc8\xa2\x04\x59\xe5\x39\xac\x55\x76\x97\xa8\x0d\xdc\xe2\xe6\xbf\x00\xd8\x3b\xb2\x35\x37\xf0\xc8\x2d\x1b\x1f\xb0\xf0\xa5\x5b\x32\x3e\x34\xcd\x2f\x2e\xcf\xaa\x52\x5c\x25\x55\x5e\xc2\x64\x32\xe2\x3c\x04\x6c\x36\xff\x91\xda\x5a\xd2\x9e\x9a\x5a\x7b\xf0\xdc\x91\xf3\xba\x1b\xe0\xc8\x7e\xd7\xef\x3f\x5e\xe0\xb5\x37\x14\xd2\x63\xf1\xb3\x76\xbe\x76\x44\xe6\x2f\x21\x4b\x87\xfe\xe9\xd7\x3b\x23\xb9\x2a\x14\x66\x37\x72\x14\x02\xd1\x49\x47\x0c\x0a\x57\xa8\x50\x2e\xf1\xfe\x5d\x91\x8b\xb8\x89\x45\xbc\x08\xc2\x33\x99\xe2\x43\x10\x5e\x9f\x72\x35\x37\x2f\x50\xc8\xaf\x21\x3e\xfb\x16\x42\x9c\x2f\x97\xf6\x47\x23\x44\xaa\x8a\xf5\xb7\xe5\x16\x6f\x03\x00\x44\x8a\x00\xe3\xe0\x01\x00\x00")
+
+func migrations014_sessionsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations014_sessionsSql,
+		"migrations/014_sessions.sql",
+	)
+}
+
+func migrations014_sessionsSql() (*asset, error) {
+	bytes, err := migrations014_sessionsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/014_sessions.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations015_known_devicesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x90\x31\x4f\xc3\x30\x14\x84\x77\xff\x8a\x1b\x63\xd1\x6e\x88\xa5\x53\x68\x5f\x51\x45\x48\x2a\x93\x0e\x9d\x22\x93\x3c\xd2\x27\x88\x13\xd9\x6e\x23\xf1\xeb\x51\x81\x21\x91\x58\xba\x9e\xbe\x93\xee\xbe\xe5\x12\x77\x9d\xb4\xde\x46\xc6\x61\x50\x6a\x6d\x28\x2d\x09\x65\xfa\x98\x11\x3e\x5c\x3f\xba\xaa\xe1\x8b\xd4\x1c\x12\x05\x9c\x03\xfb\x4a\x1a\xbc\x49\x2b\x2e\x22\x2f\x4a\xe4\x87\x2c\x5b\x28\xe0\x5d\x5c\xcb\x7e\xf0\xd7\xfc\x62\x7d\x7d\xb2\x3e\x79\xb8\xd7\x33\xa6\xf6\x6c\x23\x37\x95\x8d\x88\xd2\x71\x88\xb6\x1b\x30\x4a\x3c\xf5\xe7\xdf\x04\x5f\xbd\xe3\x59\xe5\xd3\x86\x58\x05\x66\x77\x4b\x69\x6f\x76\x2f\xa9\x39\xe2\x99\x8e\x48\xfe\x36\x2f\xa6\x0b\xf5\x95\xda\x16\x86\x76\x4f\xf9\x8c\xd2\x30\xb4\x25\x43\xf9\x9a\x5e\x7f\xde\x86\x44\x1a\xad\xf4\x4a\xa9\xa9\xa9\x4d\x3f\x3a\xa5\x36\xa6\xd8\xff\x67\x6a\xf5\x3d\x00\x0f\xac\x5a\x4b\x55\x01\x00\x00")
+
+func migrations015_known_devicesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations015_known_devicesSql,
+		"migrations/015_known_devices.sql",
+	)
+}
+
+func migrations015_known_devicesSql() (*asset, error) {
+	bytes, err := migrations015_known_devicesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/015_known_devices.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations016_login_historySql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x91\x4f\x4f\x83\x40\x10\xc5\xef\xfb\x29\xe6\x08\xd1\x1e\xac\x72\xe2\x44\xcb\xb4\x21\xe2\xd2\x6c\x69\x62\x4f\x64\x2d\x1b\x98\x84\x7f\x59\x16\xab\x7e\x7a\x43\xad\x20\xa4\xf1\x3a\xbf\x79\x2f\xf3\xde\x2c\x16\x70\x57\x52\xa6\xa5\x51\x70\x68\x18\x5b\x0b\xf4\x62\x84\xd8\x5b\x85\x08\x45\x9d\x51\x95\xe4\xd4\x9a\x5a\x7f\x5a\x0c\x80\x52\x58\x05\xdb\x3d\x8a\xc0\x0b\x81\x47\x31\xf0\x43\x18\xc2\x4e\x04\x2f\x9e\x38\xc2\x33\x1e\xef\x19\x40\xd7\x2a\x9d\x50\x0a\x6f\x94\x51\x65\xfa\x89\x2a\x25\x15\xf0\x2e\xf5\x29\x97\xda\x5a\x3a\x8e\x3d\x88\x7b\x4c\xcd\xc0\x9e\x66\xe8\xe2\x25\x33\x55\x99\x61\xc5\x79\x58\x4e\x77\xb4\x6a\xbb\x62\xe4\x8f\x33\x7c\xd2\x4a\x1a\x95\x26\xd2\x80\xa1\x52\xb5\x46\x96\x0d\x9c\xc9\xe4\x75\xf7\x33\x81\xaf\xba\x52\x13\xc9\x26\x12\x18\x6c\x79\x1f\x08\xac\x6b\x1c\x1b\x04\x6e\x50\x20\x5f\xe3\xfe\x12\xb1\xb5\x28\xb5\x99\xed\x0e\x9d\x05\xdc\xc7\xd7\x69\x67\xc9\x55\x9c\x50\xfa\x01\x11\x9f\x15\xfa\xeb\xec\xfe\xe7\x30\x9e\x7f\xdb\x64\xe4\xfd\x29\x7f\xdf\xe9\xd7\xe7\x8a\x31\x5f\x44\xbb\x5b\xef\x74\xbf\x07\x00\x23\xec\x87\xfd\xfa\x01\x00\x00")
+
+func migrations016_login_historySqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations016_login_historySql,
+		"migrations/016_login_history.sql",
+	)
+}
+
+func migrations016_login_historySql() (*asset, error) {
+	bytes, err := migrations016_login_historySqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/016_login_history.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations017_users_is_adminSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\xcc\xb1\x0a\xc2\x30\x10\x06\xe0\x3d\x4f\xf1\xef\xd2\x27\xe8\x14\xbd\x38\x9d\x89\x94\x64\x96\x13\x4f\x09\xb4\x89\xf4\x14\x5f\xdf\x55\xa4\x2f\xf0\x0d\x03\x76\x4b\x7d\xac\xf2\x52\x94\xa7\x73\x9e\x73\x98\x90\xfd\x9e\x03\xde\xa6\xab\xc1\x13\xe1\x90\xb8\x9c\x22\xaa\x5d\xe4\xb6\xd4\x86\x6b\xef\xb3\x4a\x43\x4c\x19\xb1\x30\x83\xc2\xd1\x17\xce\xb8\xcb\x6c\x3a\x3a\xf7\xcb\x52\xff\xb4\x2d\x98\xa6\x74\xfe\x97\xc7\xef\x00\x9c\x2c\x83\x1c\x90\x00\x00\x00")
+
+func migrations017_users_is_adminSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations017_users_is_adminSql,
+		"migrations/017_users_is_admin.sql",
+	)
+}
+
+func migrations017_users_is_adminSql() (*asset, error) {
+	bytes, err := migrations017_users_is_adminSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/017_users_is_admin.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations018_login_attempts_last_failure_atSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd2\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\xe2\x72\x0e\x72\x75\x0c\x71\x55\xf0\xf4\x73\x71\x8d\x50\xc8\xc9\x4f\xcf\xcc\x8b\x4f\x2c\x29\x49\xcd\x2d\x28\x29\x8e\xcf\x49\x2c\x2e\x89\x4f\x4b\xcc\xcc\x29\x2d\x4a\x8d\x4f\x2c\x89\xcf\x4c\xa9\x50\xf0\xf7\x43\x53\xa5\x81\xa6\x4a\xd3\x9a\x8b\x0b\xd9\x12\x97\xfc\xf2\x3c\x2e\x2e\x97\x20\xff\x00\xa2\x2d\xb1\x06\x0c\x00\xee\x89\x82\xe0\xa5\x00\x00\x00")
+
+func migrations018_login_attempts_last_failure_atSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations018_login_attempts_last_failure_atSql,
+		"migrations/018_login_attempts_last_failure_at.sql",
+	)
+}
+
+func migrations018_login_attempts_last_failure_atSql() (*asset, error) {
+	bytes, err := migrations018_login_attempts_last_failure_atSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/018_login_attempts_last_failure_at.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -161,8 +497,24 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"migrations/001_users.sql":  migrations001_usersSql,
-	"migrations/002_tokens.sql": migrations002_tokensSql,
+	"migrations/001_users.sql":                          migrations001_usersSql,
+	"migrations/002_tokens.sql":                         migrations002_tokensSql,
+	"migrations/003_users_email_verified.sql":           migrations003_users_email_verifiedSql,
+	"migrations/004_tokens_purpose.sql":                 migrations004_tokens_purposeSql,
+	"migrations/005_refresh_tokens.sql":                 migrations005_refresh_tokensSql,
+	"migrations/006_token_revocation.sql":               migrations006_token_revocationSql,
+	"migrations/007_mfa_totp.sql":                       migrations007_mfa_totpSql,
+	"migrations/008_mfa_recovery_codes.sql":             migrations008_mfa_recovery_codesSql,
+	"migrations/009_webauthn.sql":                       migrations009_webauthnSql,
+	"migrations/010_password_history.sql":               migrations010_password_historySql,
+	"migrations/011_login_attempts.sql":                 migrations011_login_attemptsSql,
+	"migrations/012_rate_limits.sql":                    migrations012_rate_limitsSql,
+	"migrations/013_tokens_payload.sql":                 migrations013_tokens_payloadSql,
+	"migrations/014_sessions.sql":                       migrations014_sessionsSql,
+	"migrations/015_known_devices.sql":                  migrations015_known_devicesSql,
+	"migrations/016_login_history.sql":                  migrations016_login_historySql,
+	"migrations/017_users_is_admin.sql":                 migrations017_users_is_adminSql,
+	"migrations/018_login_attempts_last_failure_at.sql": migrations018_login_attempts_last_failure_atSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -207,8 +559,24 @@ type bintree struct {
 
 var _bintree = &bintree{nil, map[string]*bintree{
 	"migrations": &bintree{nil, map[string]*bintree{
-		"001_users.sql":  &bintree{migrations001_usersSql, map[string]*bintree{}},
-		"002_tokens.sql": &bintree{migrations002_tokensSql, map[string]*bintree{}},
+		"001_users.sql":                          &bintree{migrations001_usersSql, map[string]*bintree{}},
+		"002_tokens.sql":                         &bintree{migrations002_tokensSql, map[string]*bintree{}},
+		"003_users_email_verified.sql":           &bintree{migrations003_users_email_verifiedSql, map[string]*bintree{}},
+		"004_tokens_purpose.sql":                 &bintree{migrations004_tokens_purposeSql, map[string]*bintree{}},
+		"005_refresh_tokens.sql":                 &bintree{migrations005_refresh_tokensSql, map[string]*bintree{}},
+		"006_token_revocation.sql":               &bintree{migrations006_token_revocationSql, map[string]*bintree{}},
+		"007_mfa_totp.sql":                       &bintree{migrations007_mfa_totpSql, map[string]*bintree{}},
+		"008_mfa_recovery_codes.sql":             &bintree{migrations008_mfa_recovery_codesSql, map[string]*bintree{}},
+		"009_webauthn.sql":                       &bintree{migrations009_webauthnSql, map[string]*bintree{}},
+		"010_password_history.sql":               &bintree{migrations010_password_historySql, map[string]*bintree{}},
+		"011_login_attempts.sql":                 &bintree{migrations011_login_attemptsSql, map[string]*bintree{}},
+		"012_rate_limits.sql":                    &bintree{migrations012_rate_limitsSql, map[string]*bintree{}},
+		"013_tokens_payload.sql":                 &bintree{migrations013_tokens_payloadSql, map[string]*bintree{}},
+		"014_sessions.sql":                       &bintree{migrations014_sessionsSql, map[string]*bintree{}},
+		"015_known_devices.sql":                  &bintree{migrations015_known_devicesSql, map[string]*bintree{}},
+		"016_login_history.sql":                  &bintree{migrations016_login_historySql, map[string]*bintree{}},
+		"017_users_is_admin.sql":                 &bintree{migrations017_users_is_adminSql, map[string]*bintree{}},
+		"018_login_attempts_last_failure_at.sql": &bintree{migrations018_login_attempts_last_failure_atSql, map[string]*bintree{}},
 	}},
 }}
 
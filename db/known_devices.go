@@ -0,0 +1,60 @@
+package db
+
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// KnownDevice is a client fingerprint the user has already signed in from
+type KnownDevice struct {
+	UserID      uint64    `db:"pk,user_id"`
+	Fingerprint string    `db:"pk,fingerprint"`
+	CreatedAt   time.Time `db:"created_at"`
+	LastSeenAt  time.Time `db:"last_seen_at"`
+}
+
+func (d KnownDevice) TableName() string {
+	return "known_devices"
+}
+
+func (d *DB) CountKnownDevices(userID uint64) (int, error) {
+	var count int
+	err := d.db.Select("COUNT(*)").
+		From("known_devices").
+		Where(dbx.HashExp{"user_id": userID}).
+		Row(&count)
+	return count, err
+}
+
+// RememberDevice stores the device or updates the last time it was seen,
+// it returns true if the device was not known before
+func (d *DB) RememberDevice(device *KnownDevice) (bool, error) {
+	result, err := d.db.NewQuery(`INSERT INTO known_devices (user_id, fingerprint, created_at, last_seen_at)
+		VALUES ({:user_id}, {:fingerprint}, {:created_at}, {:last_seen_at})
+		ON CONFLICT (user_id, fingerprint) DO NOTHING`).
+		Bind(dbx.Params{
+			"user_id":      device.UserID,
+			"fingerprint":  device.Fingerprint,
+			"created_at":   device.CreatedAt,
+			"last_seen_at": device.LastSeenAt,
+		}).
+		Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if affected > 0 {
+		return true, nil
+	}
+
+	params := dbx.Params{"last_seen_at": device.LastSeenAt}
+	expression := dbx.HashExp{"user_id": device.UserID, "fingerprint": device.Fingerprint}
+	_, err = d.db.Update("known_devices", params, expression).Execute()
+	return false, err
+}
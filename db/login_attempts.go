@@ -0,0 +1,71 @@
+package db
+
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// LoginAttempt counts the failed logins of an account or a client IP
+type LoginAttempt struct {
+	Key           string     `db:"pk,key"`
+	Failures      int        `db:"failures"`
+	LastFailureAt time.Time  `db:"last_failure_at"`
+	LockedUntil   *time.Time `db:"locked_until"`
+}
+
+func (a LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// UpdateLoginAttempt runs fn on the attempt of the key locked for update and
+// stores the result, an attempt without failures is passed for a new key
+func (d *DB) UpdateLoginAttempt(key string, fn func(attempt *LoginAttempt)) error {
+	return d.db.Transactional(func(tx *dbx.Tx) error {
+		_, err := tx.NewQuery(`INSERT INTO login_attempts (key, failures, last_failure_at)
+			VALUES ({:key}, 0, {:last_failure_at})
+			ON CONFLICT (key) DO NOTHING`).
+			Bind(dbx.Params{"key": key, "last_failure_at": time.Time{}}).
+			Execute()
+		if err != nil {
+			return err
+		}
+
+		var attempt LoginAttempt
+		err = tx.NewQuery(`SELECT key, failures, last_failure_at, locked_until
+			FROM login_attempts WHERE key = {:key} FOR UPDATE`).
+			Bind(dbx.Params{"key": key}).
+			One(&attempt)
+		if err != nil {
+			return err
+		}
+
+		fn(&attempt)
+
+		params := dbx.Params{
+			"failures":        attempt.Failures,
+			"last_failure_at": attempt.LastFailureAt,
+			"locked_until":    attempt.LockedUntil,
+		}
+		_, err = tx.Update("login_attempts", params, dbx.HashExp{"key": key}).Execute()
+		return err
+	})
+}
+
+func (d *DB) DeleteLoginAttempt(key string) error {
+	_, err := d.db.Delete("login_attempts", dbx.HashExp{"key": key}).Execute()
+	return err
+}
+
+// DeleteStaleLoginAttempts drops the rows with failures before since which
+// are not locked anymore
+func (d *DB) DeleteStaleLoginAttempts(now, since time.Time) error {
+	_, err := d.db.Delete("login_attempts", dbx.And(
+		dbx.NewExp("last_failure_at < {:since}", dbx.Params{"since": since}),
+		dbx.Or(
+			dbx.HashExp{"locked_until": nil},
+			dbx.NewExp("locked_until < {:now}", dbx.Params{"now": now}),
+		),
+	)).Execute()
+	return err
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// LoginResult is the outcome of a login attempt
+type LoginResult string
+
+const (
+	LoginResultSuccess          LoginResult = "success"
+	LoginResultBadPassword      LoginResult = "bad_password"
+	LoginResultLocked           LoginResult = "locked"
+	LoginResultMFAFailed        LoginResult = "mfa_failed"
+	LoginResultEmailNotVerified LoginResult = "email_not_verified"
+)
+
+// LoginHistory is a login attempt, UserID is nil if the attempted email
+// doesn't belong to any user
+type LoginHistory struct {
+	ID        uint64      `db:"id"`
+	UserID    *uint64     `db:"user_id"`
+	Email     string      `db:"email"`
+	IP        string      `db:"ip"`
+	UserAgent string      `db:"user_agent"`
+	Result    LoginResult `db:"result"`
+	CreatedAt time.Time   `db:"created_at"`
+}
+
+func (h LoginHistory) TableName() string {
+	return "login_history"
+}
+
+// LoginHistoryFilter narrows down the login history, zero fields match
+// every attempt
+type LoginHistoryFilter struct {
+	UserID *uint64
+	Email  string
+	IP     string
+	Result LoginResult
+	Since  *time.Time
+	Until  *time.Time
+	Limit  int
+	Offset int
+}
+
+func (d *DB) CreateLoginHistory(entry *LoginHistory) error {
+	return d.db.Model(entry).Insert()
+}
+
+// GetLoginHistory returns the attempts matching the filter, newest first
+func (d *DB) GetLoginHistory(filter LoginHistoryFilter) ([]LoginHistory, error) {
+	query := d.db.Select().From("login_history")
+
+	if filter.UserID != nil {
+		query.AndWhere(dbx.HashExp{"user_id": *filter.UserID})
+	}
+
+	if filter.Email != "" {
+		query.AndWhere(dbx.NewExp("LOWER(email) = LOWER({:email})", dbx.Params{"email": filter.Email}))
+	}
+
+	if filter.IP != "" {
+		query.AndWhere(dbx.HashExp{"ip": filter.IP})
+	}
+
+	if filter.Result != "" {
+		query.AndWhere(dbx.HashExp{"result": filter.Result})
+	}
+
+	if filter.Since != nil {
+		query.AndWhere(dbx.NewExp("created_at >= {:since}", dbx.Params{"since": *filter.Since}))
+	}
+
+	if filter.Until != nil {
+		query.AndWhere(dbx.NewExp("created_at < {:until}", dbx.Params{"until": *filter.Until}))
+	}
+
+	var history []LoginHistory
+	err := query.
+		OrderBy("id DESC").
+		Limit(int64(filter.Limit)).
+		Offset(int64(filter.Offset)).
+		All(&history)
+	return history, err
+}
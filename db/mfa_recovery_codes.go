@@ -0,0 +1,66 @@
+package db
+
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// RecoveryCode is a single-use replacement of the TOTP code, only its hash is stored
+type RecoveryCode struct {
+	ID        uint64     `db:"id"`
+	UserID    uint64     `db:"user_id"`
+	CodeHash  string     `db:"code_hash"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+func (c RecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// ReplaceRecoveryCodes drops every previous code of the user, used or not,
+// and stores the new set
+func (d *DB) ReplaceRecoveryCodes(userID uint64, codes []*RecoveryCode) error {
+	return d.db.Transactional(func(tx *dbx.Tx) error {
+		if _, err := tx.Delete("mfa_recovery_codes", dbx.HashExp{"user_id": userID}).Execute(); err != nil {
+			return err
+		}
+
+		for _, code := range codes {
+			if err := tx.Model(code).Insert(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (d *DB) GetUnusedRecoveryCodes(userID uint64) ([]RecoveryCode, error) {
+	var codes []RecoveryCode
+	err := d.db.Select().
+		Where(dbx.HashExp{"user_id": userID, "used_at": nil}).
+		OrderBy("id").
+		All(&codes)
+	return codes, err
+}
+
+// UseRecoveryCode returns false if the code was already used concurrently
+func (d *DB) UseRecoveryCode(id uint64, usedAt time.Time) (bool, error) {
+	result, err := d.db.Update("mfa_recovery_codes",
+		dbx.Params{"used_at": usedAt},
+		dbx.HashExp{"id": id, "used_at": nil},
+	).Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (d *DB) DeleteRecoveryCodes(userID uint64) error {
+	_, err := d.db.Delete("mfa_recovery_codes", dbx.HashExp{"user_id": userID}).Execute()
+	return err
+}
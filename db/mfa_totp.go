@@ -0,0 +1,73 @@
+package db
+
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// TOTP is the second factor of the user, the secret is stored encrypted.
+// MFA is enabled only once the enrollment is confirmed with the first code.
+type TOTP struct {
+	UserID       uint64     `db:"pk,user_id"`
+	Secret       []byte     `db:"secret"`
+	ConfirmedAt  *time.Time `db:"confirmed_at"`
+	LastUsedStep int64      `db:"last_used_step"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+func (t TOTP) TableName() string {
+	return "mfa_totp"
+}
+
+func (t TOTP) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+func (d *DB) GetTOTP(userID uint64) (*TOTP, error) {
+	var totp TOTP
+	err := d.db.Select().Model(userID, &totp)
+	return &totp, err
+}
+
+// SetTOTP starts a new enrollment replacing an unconfirmed one
+func (d *DB) SetTOTP(totp *TOTP) error {
+	_, err := d.db.Upsert("mfa_totp", dbx.Params{
+		"user_id":        totp.UserID,
+		"secret":         totp.Secret,
+		"confirmed_at":   totp.ConfirmedAt,
+		"last_used_step": totp.LastUsedStep,
+		"created_at":     totp.CreatedAt,
+	}, "user_id").Execute()
+	return err
+}
+
+// UseTOTPStep records the time step of an accepted code. It returns false if
+// the same or a later step was already used, so a code is never accepted twice.
+func (d *DB) UseTOTPStep(userID uint64, step int64) (bool, error) {
+	result, err := d.db.Update("mfa_totp",
+		dbx.Params{"last_used_step": step},
+		dbx.And(
+			dbx.HashExp{"user_id": userID},
+			dbx.NewExp("last_used_step < {:step}", dbx.Params{"step": step}),
+		),
+	).Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (d *DB) ConfirmTOTP(userID uint64, confirmedAt time.Time) error {
+	params := dbx.Params{"confirmed_at": confirmedAt}
+	expression := dbx.HashExp{"user_id": userID}
+	_, err := d.db.Update("mfa_totp", params, expression).Execute()
+	return err
+}
+
+func (d *DB) DeleteTOTP(userID uint64) error {
+	_, err := d.db.Delete("mfa_totp", dbx.HashExp{"user_id": userID}).Execute()
+	return err
+}
@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE users ADD COLUMN email_verified_at timestamp without time zone;
+
+-- +migrate Down
+
+ALTER TABLE users DROP COLUMN email_verified_at;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- +migrate Up
+
+-- tokens created before purposes existed cannot be attributed to a flow,
+-- so they are added as expired and can no longer be consumed
+ALTER TABLE tokens
+  ADD COLUMN purpose varchar(32) NOT NULL DEFAULT '',
+  ADD COLUMN expires_at timestamp without time zone NOT NULL DEFAULT now();
+
+ALTER TABLE tokens
+  ALTER COLUMN purpose DROP DEFAULT,
+  ALTER COLUMN expires_at DROP DEFAULT;
+
+CREATE INDEX tokens_user_id_purpose_idx ON tokens(user_id, purpose);
+
+-- +migrate Down
+
+DROP INDEX tokens_user_id_purpose_idx;
+
+ALTER TABLE tokens
+  DROP COLUMN purpose,
+  DROP COLUMN expires_at;
\ No newline at end of file
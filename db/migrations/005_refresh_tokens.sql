@@ -0,0 +1,18 @@
+-- +migrate Up
+
+CREATE TABLE refresh_tokens(
+  id varchar(64) PRIMARY KEY,
+  family_id varchar(64) NOT NULL,
+  user_id bigint NOT NULL,
+  created_at timestamp without time zone NOT NULL,
+  expires_at timestamp without time zone NOT NULL,
+  rotated_at timestamp without time zone,
+  revoked_at timestamp without time zone,
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens(family_id);
+
+-- +migrate Down
+
+DROP TABLE refresh_tokens;
\ No newline at end of file
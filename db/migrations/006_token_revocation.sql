@@ -0,0 +1,16 @@
+-- +migrate Up
+
+ALTER TABLE users ADD COLUMN token_generation bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE revoked_tokens(
+  jti varchar(64) PRIMARY KEY,
+  user_id bigint NOT NULL,
+  expires_at timestamp without time zone NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- +migrate Down
+
+DROP TABLE revoked_tokens;
+
+ALTER TABLE users DROP COLUMN token_generation;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +migrate Up
+
+CREATE TABLE mfa_totp(
+  user_id bigint PRIMARY KEY,
+  secret bytea NOT NULL,
+  confirmed_at timestamp without time zone,
+  last_used_step bigint NOT NULL DEFAULT 0,
+  created_at timestamp without time zone NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- +migrate Down
+
+DROP TABLE mfa_totp;
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- +migrate Up
+
+CREATE TABLE mfa_recovery_codes(
+  id BIGSERIAL NOT NULL PRIMARY KEY,
+  user_id bigint NOT NULL,
+  code_hash varchar(255) NOT NULL,
+  used_at timestamp without time zone,
+  created_at timestamp without time zone NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX mfa_recovery_codes_user_id_idx ON mfa_recovery_codes(user_id);
+
+-- +migrate Down
+
+DROP TABLE mfa_recovery_codes;
\ No newline at end of file
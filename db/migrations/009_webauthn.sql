@@ -0,0 +1,29 @@
+-- +migrate Up
+
+CREATE TABLE webauthn_credentials(
+  id bytea NOT NULL PRIMARY KEY,
+  user_id bigint NOT NULL,
+  public_key bytea NOT NULL,
+  attestation_type varchar(32) NOT NULL,
+  aaguid bytea NOT NULL,
+  sign_count bigint NOT NULL DEFAULT 0,
+  created_at timestamp without time zone NOT NULL,
+  last_used_at timestamp without time zone,
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX webauthn_credentials_user_id_idx ON webauthn_credentials(user_id);
+
+CREATE TABLE webauthn_sessions(
+  id varchar(64) NOT NULL PRIMARY KEY,
+  user_id bigint NOT NULL,
+  purpose varchar(16) NOT NULL,
+  data text NOT NULL,
+  expires_at timestamp without time zone NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- +migrate Down
+
+DROP TABLE webauthn_sessions;
+DROP TABLE webauthn_credentials;
\ No newline at end of file
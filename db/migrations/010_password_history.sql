@@ -0,0 +1,15 @@
+-- +migrate Up
+
+CREATE TABLE password_history(
+  id BIGSERIAL NOT NULL PRIMARY KEY,
+  user_id bigint NOT NULL,
+  password varchar(255) NOT NULL,
+  created_at timestamp without time zone NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX password_history_user_id_idx ON password_history(user_id);
+
+-- +migrate Down
+
+DROP TABLE password_history;
\ No newline at end of file
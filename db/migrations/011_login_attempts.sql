@@ -0,0 +1,12 @@
+-- +migrate Up
+
+CREATE TABLE login_attempts(
+  key varchar(255) NOT NULL PRIMARY KEY,
+  failures integer NOT NULL DEFAULT 0,
+  last_failure_at timestamp without time zone NOT NULL,
+  locked_until timestamp without time zone
+);
+
+-- +migrate Down
+
+DROP TABLE login_attempts;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- +migrate Up
+
+CREATE TABLE rate_limits(
+  key varchar(255) NOT NULL,
+  window_start timestamp without time zone NOT NULL,
+  hits integer NOT NULL DEFAULT 0,
+  expires_at timestamp without time zone NOT NULL,
+  PRIMARY KEY (key, window_start)
+);
+
+CREATE INDEX rate_limits_expires_at_idx ON rate_limits(expires_at);
+
+-- +migrate Down
+
+DROP TABLE rate_limits;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- +migrate Up
+
+-- payload carries the data of a flow, e.g. the new address of an email change
+ALTER TABLE tokens ADD COLUMN payload varchar(254) NOT NULL DEFAULT '';
+
+-- +migrate Down
+
+ALTER TABLE tokens DROP COLUMN payload;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- +migrate Up
+
+CREATE TABLE sessions(
+  id varchar(64) NOT NULL PRIMARY KEY,
+  user_id bigint NOT NULL,
+  user_agent varchar(512) NOT NULL DEFAULT '',
+  ip varchar(45) NOT NULL DEFAULT '',
+  created_at timestamp without time zone NOT NULL,
+  last_seen_at timestamp without time zone NOT NULL,
+  revoked_at timestamp without time zone,
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions(user_id);
+
+-- +migrate Down
+
+DROP TABLE sessions;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +migrate Up
+
+CREATE TABLE known_devices(
+  user_id bigint NOT NULL,
+  fingerprint varchar(64) NOT NULL,
+  created_at timestamp without time zone NOT NULL,
+  last_seen_at timestamp without time zone NOT NULL,
+  PRIMARY KEY (user_id, fingerprint),
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- +migrate Down
+
+DROP TABLE known_devices;
\ No newline at end of file
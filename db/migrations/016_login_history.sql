@@ -0,0 +1,19 @@
+-- +migrate Up
+
+CREATE TABLE login_history(
+  id BIGSERIAL NOT NULL PRIMARY KEY,
+  user_id bigint,
+  email varchar(255) NOT NULL,
+  ip varchar(45) NOT NULL,
+  user_agent varchar(512) NOT NULL,
+  result varchar(32) NOT NULL,
+  created_at timestamp without time zone NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX login_history_user_id_idx ON login_history(user_id);
+CREATE INDEX login_history_created_at_idx ON login_history(created_at);
+
+-- +migrate Down
+
+DROP TABLE login_history;
\ No newline at end of file
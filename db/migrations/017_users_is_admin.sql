@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE users ADD COLUMN is_admin boolean NOT NULL DEFAULT false;
+
+-- +migrate Down
+
+ALTER TABLE users DROP COLUMN is_admin;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- +migrate Up
+
+CREATE INDEX login_attempts_last_failure_at_idx ON login_attempts(last_failure_at);
+
+-- +migrate Down
+
+DROP INDEX login_attempts_last_failure_at_idx;
\ No newline at end of file
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// PasswordHistory is a hash of a password the user had set, kept to reject
+// setting it again
+type PasswordHistory struct {
+	ID        uint64    `db:"id"`
+	UserID    uint64    `db:"user_id"`
+	Password  string    `db:"password"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (h PasswordHistory) TableName() string {
+	return "password_history"
+}
+
+// GetPasswordHistory returns the previous passwords of the user, newest first
+func (d *DB) GetPasswordHistory(userID uint64) ([]PasswordHistory, error) {
+	var history []PasswordHistory
+	err := d.db.Select().
+		Where(dbx.HashExp{"user_id": userID}).
+		OrderBy("id DESC").
+		All(&history)
+	return history, err
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// IncrementRateLimit atomically counts a hit of the key in the window starting
+// at start and returns the hits of this window and of the previous one
+func (d *DB) IncrementRateLimit(key string, start time.Time, window time.Duration) (int, int, error) {
+	var current, previous int
+	err := d.db.Transactional(func(tx *dbx.Tx) error {
+		err := tx.NewQuery(`INSERT INTO rate_limits (key, window_start, hits, expires_at)
+			VALUES ({:key}, {:start}, 1, {:expires_at})
+			ON CONFLICT (key, window_start) DO UPDATE SET hits = rate_limits.hits + 1
+			RETURNING hits`).
+			Bind(dbx.Params{"key": key, "start": start, "expires_at": start.Add(2 * window)}).
+			Row(&current)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Select("hits").
+			From("rate_limits").
+			Where(dbx.HashExp{"key": key, "window_start": start.Add(-window)}).
+			Row(&previous)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+
+		return err
+	})
+
+	return current, previous, err
+}
+
+// DeleteExpiredRateLimits drops the windows which can't affect the limits anymore
+func (d *DB) DeleteExpiredRateLimits(now time.Time) error {
+	_, err := d.db.Delete("rate_limits",
+		dbx.NewExp("expires_at < {:now}", dbx.Params{"now": now}),
+	).Execute()
+	return err
+}
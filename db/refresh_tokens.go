@@ -0,0 +1,70 @@
+package db
+
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// RefreshToken is stored by the hash of the token handed out to the client.
+// Every rotation inserts a new token into the same family, so reuse of a
+// rotated token can be traced back to the whole chain.
+type RefreshToken struct {
+	ID        string     `db:"pk,id"`
+	FamilyID  string     `db:"family_id"`
+	UserID    uint64     `db:"user_id"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RotatedAt *time.Time `db:"rotated_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+func (t RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+func (d *DB) CreateRefreshToken(token *RefreshToken) error {
+	return d.db.Model(token).Insert()
+}
+
+func (d *DB) GetRefreshToken(id string) (*RefreshToken, error) {
+	var token RefreshToken
+	err := d.db.Select().Model(id, &token)
+	return &token, err
+}
+
+// RotateRefreshToken marks the token as rotated and stores its successor.
+// It returns false if the token was already rotated or revoked concurrently.
+func (d *DB) RotateRefreshToken(id string, next *RefreshToken) (bool, error) {
+	rotated := false
+	err := d.db.Transactional(func(tx *dbx.Tx) error {
+		result, err := tx.Update("refresh_tokens",
+			dbx.Params{"rotated_at": time.Now()},
+			dbx.HashExp{"id": id, "rotated_at": nil, "revoked_at": nil},
+		).Execute()
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return nil
+		}
+
+		rotated = true
+		return tx.Model(next).Insert()
+	})
+
+	return rotated, err
+}
+
+func (d *DB) RevokeRefreshTokenFamily(familyID string) error {
+	params := dbx.Params{"revoked_at": time.Now()}
+	expression := dbx.HashExp{"family_id": familyID, "revoked_at": nil}
+	_, err := d.db.Update("refresh_tokens", params, expression).Execute()
+	return err
+}
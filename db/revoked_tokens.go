@@ -0,0 +1,45 @@
+package db
+
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// RevokedToken is an access token invalidated before its expiration,
+// it is kept until the token would have expired on its own.
+type RevokedToken struct {
+	JTI       string    `db:"pk,jti"`
+	UserID    uint64    `db:"user_id"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+func (t RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+func (d *DB) RevokeToken(token *RevokedToken) error {
+	_, err := d.db.Upsert("revoked_tokens", dbx.Params{
+		"jti":        token.JTI,
+		"user_id":    token.UserID,
+		"expires_at": token.ExpiresAt,
+	}, "jti").Execute()
+	if err != nil {
+		return err
+	}
+
+	//drop the records which can't be presented anymore
+	_, err = d.db.Delete("revoked_tokens",
+		dbx.NewExp("expires_at < {:now}", dbx.Params{"now": time.Now()}),
+	).Execute()
+	return err
+}
+
+func (d *DB) IsTokenRevoked(jti string) (bool, error) {
+	var count int
+	err := d.db.Select("COUNT(*)").
+		From("revoked_tokens").
+		Where(dbx.HashExp{"jti": jti}).
+		Row(&count)
+	return count > 0, err
+}
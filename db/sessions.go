@@ -0,0 +1,70 @@
+package db
+
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// Session is a single login of the user. It is stored by the hash of the
+// refresh token family ID which is carried by the access tokens of the login.
+type Session struct {
+	ID         string     `db:"pk,id"`
+	UserID     uint64     `db:"user_id"`
+	UserAgent  string     `db:"user_agent"`
+	IP         string     `db:"ip"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastSeenAt time.Time  `db:"last_seen_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+func (s Session) TableName() string {
+	return "sessions"
+}
+
+func (d *DB) CreateSession(session *Session) error {
+	return d.db.Model(session).Insert()
+}
+
+func (d *DB) GetSession(id string) (*Session, error) {
+	var session Session
+	err := d.db.Select().Model(id, &session)
+	return &session, err
+}
+
+// GetUserSessions returns the sessions which are not revoked and were seen
+// after since, the most recently used first
+func (d *DB) GetUserSessions(userID uint64, since time.Time) ([]Session, error) {
+	var sessions []Session
+	err := d.db.Select().
+		Where(dbx.HashExp{"user_id": userID, "revoked_at": nil}).
+		AndWhere(dbx.NewExp("last_seen_at > {:since}", dbx.Params{"since": since})).
+		OrderBy("last_seen_at DESC").
+		All(&sessions)
+	return sessions, err
+}
+
+func (d *DB) TouchSession(id string, seenAt time.Time) error {
+	params := dbx.Params{"last_seen_at": seenAt}
+	expression := dbx.HashExp{"id": id, "revoked_at": nil}
+	_, err := d.db.Update("sessions", params, expression).Execute()
+	return err
+}
+
+// RevokeSession returns false if the user has no such session or it is
+// already revoked
+func (d *DB) RevokeSession(userID uint64, id string) (bool, error) {
+	params := dbx.Params{"revoked_at": time.Now()}
+	expression := dbx.HashExp{"id": id, "user_id": userID, "revoked_at": nil}
+	result, err := d.db.Update("sessions", params, expression).Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -1,11 +1,31 @@
 package db
 
-import "time"
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// TokenPurpose restricts the flow in which a token can be consumed.
+type TokenPurpose string
+
+const (
+	TokenPurposeVerifyEmail   TokenPurpose = "verify_email"
+	TokenPurposeResetPassword TokenPurpose = "reset_password"
+	TokenPurposeChangeEmail   TokenPurpose = "change_email"
+	TokenPurposeMagicLink     TokenPurpose = "magic_link"
+	TokenPurposeMFAChallenge  TokenPurpose = "mfa_challenge"
+	TokenPurposeRevertEmail   TokenPurpose = "revert_email"
+	TokenPurposeRevokeSession TokenPurpose = "revoke_session"
+)
 
 type Token struct {
-	Token      string    `db:"pk,token"`
-	UserID     uint64    `db:"user_id"`
-	LastSentAt time.Time `db:"last_sent_at"`
+	Token      string       `db:"pk,token"`
+	UserID     uint64       `db:"user_id"`
+	Purpose    TokenPurpose `db:"purpose"`
+	LastSentAt time.Time    `db:"last_sent_at"`
+	ExpiresAt  time.Time    `db:"expires_at"`
+	Payload    string       `db:"payload"`
 }
 
 func (t Token) TableName() string {
@@ -16,13 +36,62 @@ func (d *DB) CreateToken(token *Token) error {
 	return d.db.Model(token).Insert()
 }
 
-func (d *DB) GetUserByToken(tokenID string) (*Token, error) {
+// GetUserByToken returns the token only if it was issued for the given purpose
+// and has not expired yet, otherwise sql.ErrNoRows is returned.
+func (d *DB) GetUserByToken(tokenID string, purpose TokenPurpose) (*Token, error) {
+	var token Token
+	err := d.db.Select().
+		Where(dbx.HashExp{"token": tokenID, "purpose": purpose}).
+		AndWhere(dbx.NewExp("expires_at > {:now}", dbx.Params{"now": time.Now()})).
+		One(&token)
+	return &token, err
+}
+
+// GetLastUserToken returns the latest token of the user issued for the purpose
+// which has not expired yet, otherwise sql.ErrNoRows is returned.
+func (d *DB) GetLastUserToken(userID uint64, purpose TokenPurpose) (*Token, error) {
 	var token Token
-	err := d.db.Select().Model(tokenID, &token)
+	err := d.db.Select().
+		Where(dbx.HashExp{"user_id": userID, "purpose": purpose}).
+		AndWhere(dbx.NewExp("expires_at > {:now}", dbx.Params{"now": time.Now()})).
+		OrderBy("expires_at DESC").
+		Limit(1).
+		One(&token)
 	return &token, err
 }
 
-func (d *DB) DeleteToken(tokenID string) error {
-	token := &Token{Token: tokenID}
-	return d.db.Model(token).Delete()
+// TouchToken sets the time the token was sent at. It returns false if the
+// token was sent after notAfter, e.g. by a concurrent request.
+func (d *DB) TouchToken(tokenID string, sentAt, notAfter time.Time) (bool, error) {
+	result, err := d.db.Update("tokens",
+		dbx.Params{"last_sent_at": sentAt},
+		dbx.And(
+			dbx.HashExp{"token": tokenID},
+			dbx.NewExp("last_sent_at <= {:not_after}", dbx.Params{"not_after": notAfter}),
+		),
+	).Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// UseToken deletes the token and returns false if it was already deleted,
+// so a single-use token is never consumed by two concurrent requests
+func (d *DB) UseToken(tokenID string) (bool, error) {
+	result, err := d.db.Delete("tokens", dbx.HashExp{"token": tokenID}).Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// DeleteUserTokens drops every token of the user issued for the purpose
+func (d *DB) DeleteUserTokens(userID uint64, purpose TokenPurpose) error {
+	_, err := d.db.Delete("tokens", dbx.HashExp{"user_id": userID, "purpose": purpose}).Execute()
+	return err
 }
@@ -1,20 +1,33 @@
 package db
 
-import "github.com/go-ozzo/ozzo-dbx"
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
 
 type User struct {
-	ID          uint64 `db:"id"`
-	Email       string `db:"email"`
-	Password    string `db:"password"`
-	Name        string `db:"name"`
-	Phone       string `db:"phone"`
-	DateOfBirth string `db:"date_of_birth"`
+	ID              uint64     `db:"id"`
+	Email           string     `db:"email"`
+	Password        string     `db:"password"`
+	Name            string     `db:"name"`
+	Phone           string     `db:"phone"`
+	DateOfBirth     string     `db:"date_of_birth"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	TokenGeneration int64      `db:"token_generation"`
+	IsAdmin         bool       `db:"is_admin"`
 }
 
 func (u User) TableName() string {
 	return "users"
 }
 
+func (u User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
 func (d *DB) GetUser(email string) (*User, error) {
 	user := &User{}
 	err := d.db.Select().Where(dbx.HashExp{"email": email}).One(user)
@@ -31,9 +44,171 @@ func (d *DB) CreateUser(user *User) error {
 	return d.db.Model(user).Insert()
 }
 
-func (d *DB) SetUserNewPassword(user *User) error {
-	params := dbx.Params{"password": user.Password}
+// SetUserNewPassword updates the password and records the replaced one in the
+// password history, only the last historySize passwords of the user are kept
+func (d *DB) SetUserNewPassword(user *User, historySize int) error {
+	return d.db.Transactional(func(tx *dbx.Tx) error {
+		var previous string
+		err := tx.NewQuery("SELECT password FROM users WHERE id = {:id} FOR UPDATE").
+			Bind(dbx.Params{"id": user.ID}).
+			Row(&previous)
+		if err != nil {
+			return err
+		}
+
+		params := dbx.Params{"password": user.Password}
+		expression := dbx.HashExp{"id": user.ID}
+		if _, err := tx.Update("users", params, expression).Execute(); err != nil {
+			return err
+		}
+
+		if historySize > 0 {
+			err := tx.Model(&PasswordHistory{
+				UserID:    user.ID,
+				Password:  previous,
+				CreatedAt: time.Now(),
+			}).Insert()
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.NewQuery(`DELETE FROM password_history WHERE user_id = {:user_id} AND id NOT IN (
+			SELECT id FROM password_history WHERE user_id = {:user_id} ORDER BY id DESC LIMIT {:size}
+		)`).Bind(dbx.Params{"user_id": user.ID, "size": historySize}).Execute()
+		return err
+	})
+}
+
+// UpdateUserPasswordHash replaces the hash of the unchanged password
+// with the one of the current hashing algorithm and parameters
+func (d *DB) UpdateUserPasswordHash(id uint64, hash string) error {
+	params := dbx.Params{"password": hash}
+	expression := dbx.HashExp{"id": id}
+	_, err := d.db.Update("users", params, expression).Execute()
+	return err
+}
+
+func (d *DB) UpdateUserProfile(user *User) error {
+	params := dbx.Params{
+		"name":          user.Name,
+		"phone":         user.Phone,
+		"date_of_birth": user.DateOfBirth,
+	}
 	expression := dbx.HashExp{"id": user.ID}
 	_, err := d.db.Update("users", params, expression).Execute()
 	return err
 }
+
+func (d *DB) SetUserEmailVerified(id uint64, verifiedAt time.Time) error {
+	params := dbx.Params{"email_verified_at": verifiedAt}
+	expression := dbx.HashExp{"id": id}
+	_, err := d.db.Update("users", params, expression).Execute()
+	return err
+}
+
+func (d *DB) SetUserAdmin(id uint64, isAdmin bool) error {
+	params := dbx.Params{"is_admin": isAdmin}
+	expression := dbx.HashExp{"id": id}
+	_, err := d.db.Update("users", params, expression).Execute()
+	return err
+}
+
+// ErrEmailTaken is returned if another user has the email
+var ErrEmailTaken = errors.New("email is already taken")
+
+// uniqueViolation is the postgres error code of a unique constraint violation
+const uniqueViolation = "23505"
+
+// ChangeUserEmail replaces the email of the user with the verified one and
+// consumes the token of the change. It returns false if the token was already
+// consumed and ErrEmailTaken if the email was taken in the meantime.
+func (d *DB) ChangeUserEmail(id uint64, email string, verifiedAt time.Time, tokenID string) (bool, error) {
+	changed := false
+	err := d.db.Transactional(func(tx *dbx.Tx) error {
+		result, err := tx.Delete("tokens", dbx.HashExp{"token": tokenID}).Execute()
+		if err != nil {
+			return err
+		}
+
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			return err
+		}
+
+		var taken int
+		err = tx.Select("COUNT(*)").
+			From("users").
+			Where(dbx.HashExp{"email": email}).
+			AndWhere(dbx.Not(dbx.HashExp{"id": id})).
+			Row(&taken)
+		if err != nil {
+			return err
+		}
+
+		if taken > 0 {
+			return ErrEmailTaken
+		}
+
+		params := dbx.Params{"email": email, "email_verified_at": verifiedAt}
+		if _, err := tx.Update("users", params, dbx.HashExp{"id": id}).Execute(); err != nil {
+			//a concurrent signup or change took the email after the check
+			if isUniqueViolation(err) {
+				return ErrEmailTaken
+			}
+			return err
+		}
+
+		changed = true
+		return nil
+	})
+
+	return changed, err
+}
+
+// isUniqueViolation reports the unique_violation error of postgres
+func isUniqueViolation(err error) bool {
+	pqErr, ok := errors.Cause(err).(*pq.Error)
+	return ok && pqErr.Code == uniqueViolation
+}
+
+// RevokeUserSessions invalidates every access token issued to the user so far
+// by bumping its token generation and revokes all of its refresh tokens and
+// sessions.
+func (d *DB) RevokeUserSessions(id uint64) error {
+	return d.RevokeOtherUserSessions(id, "", "")
+}
+
+// RevokeOtherUserSessions does the same as RevokeUserSessions but keeps the
+// session and the refresh tokens of its family, so the current session can
+// get a new access token of the bumped generation.
+func (d *DB) RevokeOtherUserSessions(id uint64, familyID, sessionID string) error {
+	return d.db.Transactional(func(tx *dbx.Tx) error {
+		_, err := tx.Update("users",
+			dbx.Params{"token_generation": dbx.NewExp("token_generation + 1")},
+			dbx.HashExp{"id": id},
+		).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Update("refresh_tokens",
+			dbx.Params{"revoked_at": time.Now()},
+			dbx.And(
+				dbx.HashExp{"user_id": id, "revoked_at": nil},
+				dbx.Not(dbx.HashExp{"family_id": familyID}),
+			),
+		).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Update("sessions",
+			dbx.Params{"revoked_at": time.Now()},
+			dbx.And(
+				dbx.HashExp{"user_id": id, "revoked_at": nil},
+				dbx.Not(dbx.HashExp{"id": sessionID}),
+			),
+		).Execute()
+		return err
+	})
+}
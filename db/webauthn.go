@@ -0,0 +1,99 @@
+package db
+
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-dbx"
+)
+
+// WebAuthnPurpose is the ceremony a WebAuthn session was started for.
+type WebAuthnPurpose string
+
+const (
+	WebAuthnPurposeRegistration WebAuthnPurpose = "registration"
+	WebAuthnPurposeLogin        WebAuthnPurpose = "login"
+)
+
+// WebAuthnCredential is a passkey registered by the user
+type WebAuthnCredential struct {
+	ID              []byte     `db:"pk,id"`
+	UserID          uint64     `db:"user_id"`
+	PublicKey       []byte     `db:"public_key"`
+	AttestationType string     `db:"attestation_type"`
+	AAGUID          []byte     `db:"aaguid"`
+	SignCount       uint32     `db:"sign_count"`
+	CreatedAt       time.Time  `db:"created_at"`
+	LastUsedAt      *time.Time `db:"last_used_at"`
+}
+
+func (c WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// WebAuthnSession keeps the challenge of a ceremony between its begin and
+// finish requests, Data is the serialized session of the webauthn library.
+type WebAuthnSession struct {
+	ID        string          `db:"pk,id"`
+	UserID    uint64          `db:"user_id"`
+	Purpose   WebAuthnPurpose `db:"purpose"`
+	Data      string          `db:"data"`
+	ExpiresAt time.Time       `db:"expires_at"`
+}
+
+func (s WebAuthnSession) TableName() string {
+	return "webauthn_sessions"
+}
+
+func (d *DB) CreateWebAuthnCredential(credential *WebAuthnCredential) error {
+	return d.db.Model(credential).Insert()
+}
+
+func (d *DB) GetWebAuthnCredentials(userID uint64) ([]WebAuthnCredential, error) {
+	var credentials []WebAuthnCredential
+	err := d.db.Select().
+		Where(dbx.HashExp{"user_id": userID}).
+		OrderBy("created_at").
+		All(&credentials)
+	return credentials, err
+}
+
+// UseWebAuthnCredential stores the sign count reported by the authenticator
+// on a successful login
+func (d *DB) UseWebAuthnCredential(id []byte, signCount uint32, usedAt time.Time) error {
+	params := dbx.Params{"sign_count": signCount, "last_used_at": usedAt}
+	expression := dbx.HashExp{"id": id}
+	_, err := d.db.Update("webauthn_credentials", params, expression).Execute()
+	return err
+}
+
+// CreateWebAuthnSession stores the ceremony and drops the expired ones
+func (d *DB) CreateWebAuthnSession(session *WebAuthnSession) error {
+	return d.db.Transactional(func(tx *dbx.Tx) error {
+		_, err := tx.Delete("webauthn_sessions",
+			dbx.NewExp("expires_at <= {:now}", dbx.Params{"now": time.Now()}),
+		).Execute()
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(session).Insert()
+	})
+}
+
+// TakeWebAuthnSession deletes the session and returns it, so every challenge
+// is used at most once even by concurrent requests. sql.ErrNoRows is returned
+// if the session does not exist, has expired, was started for another
+// purpose or was already taken.
+func (d *DB) TakeWebAuthnSession(id string, purpose WebAuthnPurpose) (*WebAuthnSession, error) {
+	var session WebAuthnSession
+	err := d.db.NewQuery(`DELETE FROM webauthn_sessions
+		WHERE id = {:id} AND purpose = {:purpose} AND expires_at > {:now}
+		RETURNING id, user_id, purpose, data, expires_at`).
+		Bind(dbx.Params{"id": id, "purpose": purpose, "now": time.Now()}).
+		One(&session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
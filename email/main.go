@@ -1,6 +1,10 @@
 package email
 
 import (
+	"fmt"
+	"html"
+	"time"
+
 	"github.com/go-gomail/gomail"
 	"github.com/stellar/go/support/errors"
 )
@@ -9,6 +13,12 @@ type Client interface {
 	Signup(to, link string) error
 	Forgot(to, link string) error
 	NewPassword(to string) error
+	RecoveryCodeUsed(to string, remaining int) error
+	MagicLink(to, link string) error
+	AccountLocked(to string, until time.Time) error
+	ChangeEmail(to, link string) error
+	EmailChanged(to, newEmail, revertLink string) error
+	NewSignIn(to, device, ip string, at time.Time, notMeLink string) error
 }
 
 type ClientImpl struct {
@@ -71,3 +81,107 @@ func (c ClientImpl) NewPassword(to string) error {
 
 	return nil
 }
+
+func (c ClientImpl) RecoveryCodeUsed(to string, remaining int) error {
+	dialer := gomail.NewPlainDialer(c.host, c.port, c.emailAddress, c.password)
+	msg := gomail.NewMessage()
+	msg.SetAddressHeader("From", c.emailAddress, "Sender")
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", "Recovery code used to sign in")
+	msg.SetBody("text/html", fmt.Sprintf("A recovery code was just used to sign in to your account. "+
+		"You have %d unused recovery codes left.<br><br>"+
+		"If it wasn't you, please change your password and regenerate your recovery codes."+
+		"<br><br>Best Regards,<br>Sender", remaining))
+
+	if err := dialer.DialAndSend(msg); err != nil {
+		return errors.Wrap(err, "failed to send recovery code used notification")
+	}
+
+	return nil
+}
+
+func (c ClientImpl) MagicLink(to, link string) error {
+	dialer := gomail.NewPlainDialer(c.host, c.port, c.emailAddress, c.password)
+	msg := gomail.NewMessage()
+	msg.SetAddressHeader("From", c.emailAddress, "Sender")
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", "Sign in link")
+	msg.SetBody("text/html", "To sign in to your account, please click on the link: <a href=\""+link+
+		"\">"+link+"</a><br><br>The link can be used only once and expires shortly. "+
+		"If you did not request it, you can ignore this email.<br><br>Best Regards,<br>Sender")
+
+	if err := dialer.DialAndSend(msg); err != nil {
+		return errors.Wrap(err, "failed to send magic link email")
+	}
+
+	return nil
+}
+
+func (c ClientImpl) AccountLocked(to string, until time.Time) error {
+	dialer := gomail.NewPlainDialer(c.host, c.port, c.emailAddress, c.password)
+	msg := gomail.NewMessage()
+	msg.SetAddressHeader("From", c.emailAddress, "Sender")
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", "Account temporarily locked")
+	msg.SetBody("text/html", "Your account was temporarily locked after too many failed sign in attempts. "+
+		"You can sign in again after "+until.UTC().Format(time.RFC1123)+".<br><br>"+
+		"If it wasn't you, please reset your password.<br><br>Best Regards,<br>Sender")
+
+	if err := dialer.DialAndSend(msg); err != nil {
+		return errors.Wrap(err, "failed to send account locked email")
+	}
+
+	return nil
+}
+
+func (c ClientImpl) ChangeEmail(to, link string) error {
+	dialer := gomail.NewPlainDialer(c.host, c.port, c.emailAddress, c.password)
+	msg := gomail.NewMessage()
+	msg.SetAddressHeader("From", c.emailAddress, "Sender")
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", "Confirm your new email address")
+	msg.SetBody("text/html", "To use this address for your account, please click on the link: <a href=\""+link+
+		"\">"+link+"</a><br><br>If you did not request it, you can ignore this email.<br><br>Best Regards,<br>Sender")
+
+	if err := dialer.DialAndSend(msg); err != nil {
+		return errors.Wrap(err, "failed to send change email confirmation")
+	}
+
+	return nil
+}
+
+func (c ClientImpl) EmailChanged(to, newEmail, revertLink string) error {
+	dialer := gomail.NewPlainDialer(c.host, c.port, c.emailAddress, c.password)
+	msg := gomail.NewMessage()
+	msg.SetAddressHeader("From", c.emailAddress, "Sender")
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", "Your email address was changed")
+	msg.SetBody("text/html", "The email address of your account was changed to "+newEmail+".<br><br>"+
+		"If it wasn't you, please click on the link to restore this address and sign out everywhere: <a href=\""+
+		revertLink+"\">"+revertLink+"</a><br><br>Best Regards,<br>Sender")
+
+	if err := dialer.DialAndSend(msg); err != nil {
+		return errors.Wrap(err, "failed to send email changed notification")
+	}
+
+	return nil
+}
+
+func (c ClientImpl) NewSignIn(to, device, ip string, at time.Time, notMeLink string) error {
+	dialer := gomail.NewPlainDialer(c.host, c.port, c.emailAddress, c.password)
+	msg := gomail.NewMessage()
+	msg.SetAddressHeader("From", c.emailAddress, "Sender")
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", "New sign in to your account")
+	msg.SetBody("text/html", "Your account was signed in from a new device.<br><br>"+
+		"Device: "+html.EscapeString(device)+"<br>IP address: "+html.EscapeString(ip)+"<br>"+
+		"Time: "+at.UTC().Format(time.RFC1123)+"<br><br>"+
+		"If it wasn't you, please click on the link to sign this device out and reset your password: <a href=\""+
+		notMeLink+"\">"+notMeLink+"</a><br><br>Best Regards,<br>Sender")
+
+	if err := dialer.DialAndSend(msg); err != nil {
+		return errors.Wrap(err, "failed to send new sign in email")
+	}
+
+	return nil
+}
@@ -6,18 +6,20 @@ require (
 	github.com/anfimovoleh/go-chi-middlewares v1.1.0
 	github.com/anfimovoleh/httperr v0.0.0-20210821170609-2d866c9a3e7a
 	github.com/caarlos0/env v3.5.0+incompatible
-	github.com/dgrijalva/jwt-go v3.2.0+incompatible
+	github.com/duo-labs/webauthn v0.0.0-20210727191636-9f1b88ef44cc
+	github.com/fxamacker/cbor/v2 v2.2.0
 	github.com/go-chi/chi v4.0.3+incompatible
 	github.com/go-chi/cors v1.2.0
 	github.com/go-chi/jwtauth v1.2.0
-	github.com/go-chi/jwtauth/v5 v5.0.1
 	github.com/go-gomail/gomail v0.0.0-20160411212932-81ebce5c23df
 	github.com/go-ozzo/ozzo-dbx v1.5.0
 	github.com/go-ozzo/ozzo-validation/v4 v4.3.0
 	github.com/google/uuid v1.3.0
 	github.com/json-iterator/go v1.1.11
+	github.com/lestrrat-go/jwx v1.1.6
 	github.com/lib/pq v1.10.2
 	github.com/pkg/errors v0.9.1
+	github.com/pquerna/otp v1.3.0
 	github.com/rubenv/sql-migrate v0.0.0-20210614095031-55d5740dbbcc
 	github.com/sirupsen/logrus v1.8.1 // indirect
 	github.com/spf13/cast v1.4.1
@@ -0,0 +1,129 @@
+package lockout
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Counter is the state of failed attempts of a key, e.g. an account or an IP
+type Counter struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// Store keeps the counters, it has to be shared by every replica of the
+// service for the limits to hold
+type Store interface {
+	// Update runs fn on the counter of the key and stores the result, no other
+	// update of the key may run in between. A zero Counter is passed for an
+	// unknown key. Counters not locked at now and without failures after
+	// since may be dropped.
+	Update(key string, now, since time.Time, fn func(counter *Counter)) error
+	Reset(key string) error
+}
+
+// Params configure the backoff and the lockout
+type Params struct {
+	// Threshold is the amount of failures locking the key
+	Threshold int
+	// Duration is the time the key stays locked
+	Duration time.Duration
+	// BaseDelay is the wait after the second failure, it doubles with every
+	// next failure up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Window is the time after which failures are forgotten
+	Window time.Duration
+}
+
+// Limiter slows down and then locks out keys with repeated failed attempts
+type Limiter struct {
+	store  Store
+	params Params
+}
+
+func New(store Store, params Params) *Limiter {
+	return &Limiter{store: store, params: params}
+}
+
+// Attempt checks whether the key may be attempted now and counts the attempt
+// as failed up front in one atomic step, so parallel attempts can't all pass
+// the check before their failures are counted. A successful attempt has to
+// be undone by Reset or Forgive. The wait is returned if the attempt is
+// rejected, locked is true if the key got locked by this rejection.
+func (l *Limiter) Attempt(key string, now time.Time) (wait time.Duration, locked bool, err error) {
+	since := now.Add(-l.params.Window)
+
+	err = l.store.Update(key, now, since, func(counter *Counter) {
+		wait, locked = l.attempt(counter, now, since)
+	})
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to count attempt")
+	}
+
+	return wait, locked, nil
+}
+
+// Forgive takes back a single failure counted by Attempt
+func (l *Limiter) Forgive(key string, now time.Time) error {
+	err := l.store.Update(key, now, now.Add(-l.params.Window), func(counter *Counter) {
+		if counter.Failures > 0 {
+			counter.Failures--
+		}
+	})
+	return errors.Wrap(err, "failed to forgive attempt")
+}
+
+// Reset forgets the failures of the key
+func (l *Limiter) Reset(key string) error {
+	return errors.Wrap(l.store.Reset(key), "failed to reset attempts counter")
+}
+
+func (l *Limiter) attempt(counter *Counter, now, since time.Time) (time.Duration, bool) {
+	if counter.LockedUntil != nil && counter.LockedUntil.After(now) {
+		return counter.LockedUntil.Sub(now), false
+	}
+
+	if counter.LastFailureAt.Before(since) {
+		counter.Failures = 0
+	}
+
+	if l.params.Threshold > 0 && counter.Failures >= l.params.Threshold {
+		until := now.Add(l.params.Duration)
+		counter.Failures = 0
+		counter.LockedUntil = &until
+		return l.params.Duration, true
+	}
+
+	if counter.Failures > 0 {
+		retryAt := counter.LastFailureAt.Add(l.backoff(counter.Failures))
+		if retryAt.After(now) {
+			return retryAt.Sub(now), false
+		}
+	}
+
+	counter.Failures++
+	counter.LastFailureAt = now
+	return 0, false
+}
+
+// backoff grows exponentially, the first failure is not delayed to let
+// users fix a typo
+func (l *Limiter) backoff(failures int) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+
+	delay := l.params.BaseDelay
+	for i := 2; i < failures && delay < l.params.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > l.params.MaxDelay {
+		return l.params.MaxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,170 @@
+package lockout
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+var testParams = Params{
+	Threshold: 3,
+	Duration:  time.Minute,
+	BaseDelay: time.Second,
+	MaxDelay:  4 * time.Second,
+	Window:    time.Hour,
+}
+
+func TestBackoff(t *testing.T) {
+	limiter := New(NewMemoryStore(), testParams)
+
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 1, want: 0},
+		{failures: 2, want: time.Second},
+		{failures: 3, want: 2 * time.Second},
+		{failures: 4, want: 4 * time.Second},
+		{failures: 5, want: 4 * time.Second},
+		{failures: 100, want: 4 * time.Second},
+	}
+
+	for _, c := range cases {
+		if got := limiter.backoff(c.failures); got != c.want {
+			t.Errorf("backoff(%d) = %v, want %v", c.failures, got, c.want)
+		}
+	}
+}
+
+type attempt struct {
+	at     time.Duration
+	wait   time.Duration
+	locked bool
+}
+
+func runAttempts(t *testing.T, limiter *Limiter, start time.Time, attempts []attempt) {
+	t.Helper()
+
+	for i, a := range attempts {
+		wait, locked, err := limiter.Attempt("key", start.Add(a.at))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if wait != a.wait || locked != a.locked {
+			t.Errorf("attempt %d at %v = (%v, %v), want (%v, %v)", i+1, a.at, wait, locked, a.wait, a.locked)
+		}
+	}
+}
+
+func TestAttempt(t *testing.T) {
+	start := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(NewMemoryStore(), testParams)
+
+	runAttempts(t, limiter, start, []attempt{
+		{at: 0},
+		//the second attempt is not delayed
+		{at: 0},
+		{at: 500 * time.Millisecond, wait: 500 * time.Millisecond},
+		{at: time.Second},
+		//the threshold is reached, the key gets locked
+		{at: 3 * time.Second, wait: time.Minute, locked: true},
+		{at: 33 * time.Second, wait: 30 * time.Second},
+		//the lock is over and the failures are forgotten
+		{at: 63 * time.Second},
+		{at: 63 * time.Second},
+	})
+}
+
+func TestAttemptWindow(t *testing.T) {
+	start := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(NewMemoryStore(), testParams)
+
+	runAttempts(t, limiter, start, []attempt{
+		{at: 0},
+		{at: 0},
+		{at: time.Second},
+		//the failures are older than the window
+		{at: 2 * time.Hour},
+		{at: 2 * time.Hour},
+		{at: 2 * time.Hour, wait: time.Second},
+	})
+}
+
+func TestReset(t *testing.T) {
+	start := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(NewMemoryStore(), testParams)
+
+	runAttempts(t, limiter, start, []attempt{
+		{at: 0},
+		{at: 0},
+		{at: 0, wait: time.Second},
+	})
+
+	if err := limiter.Reset("key"); err != nil {
+		t.Fatal(err)
+	}
+
+	runAttempts(t, limiter, start, []attempt{
+		{at: 0},
+		{at: 0},
+	})
+}
+
+func TestForgive(t *testing.T) {
+	start := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(NewMemoryStore(), testParams)
+
+	runAttempts(t, limiter, start, []attempt{
+		{at: 0},
+		{at: 0},
+	})
+
+	//a successful attempt takes back its own failure only
+	if err := limiter.Forgive("key", start); err != nil {
+		t.Fatal(err)
+	}
+
+	runAttempts(t, limiter, start, []attempt{
+		{at: 0},
+		{at: 0, wait: time.Second},
+	})
+}
+
+func TestAttemptConcurrent(t *testing.T) {
+	now := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(NewMemoryStore(), testParams)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			wait, _, err := limiter.Attempt("key", now)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if wait == 0 {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	//only the attempts without a backoff pass the check
+	if allowed != 2 {
+		t.Errorf("allowed %d parallel attempts, want 2", allowed)
+	}
+}
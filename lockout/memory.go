@@ -0,0 +1,55 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+// memorySweepSize is the amount of keys after which the stale ones are dropped
+const memorySweepSize = 10000
+
+// MemoryStore keeps the counters in the process, it fits a single replica
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*Counter
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]*Counter)}
+}
+
+func (s *MemoryStore) Update(key string, now, since time.Time, fn func(counter *Counter)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.counters) >= memorySweepSize {
+		s.sweep(now, since)
+	}
+
+	counter, ok := s.counters[key]
+	if !ok {
+		counter = &Counter{}
+		s.counters[key] = counter
+	}
+
+	fn(counter)
+	return nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.counters, key)
+	return nil
+}
+
+// sweep drops the counters whose failures are forgotten and which are not locked
+func (s *MemoryStore) sweep(now, since time.Time) {
+	for key, counter := range s.counters {
+		locked := counter.LockedUntil != nil && counter.LockedUntil.After(now)
+		if !locked && counter.LastFailureAt.Before(since) {
+			delete(s.counters, key)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+// postgresCleanupInterval limits how often the stale counters are dropped
+const postgresCleanupInterval = time.Minute
+
+// PostgresStore keeps the counters in the login_attempts table, so they are
+// shared by every replica
+type PostgresStore struct {
+	db *db.DB
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewPostgresStore(db *db.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Update(key string, now, since time.Time, fn func(counter *Counter)) error {
+	if err := s.cleanup(now, since); err != nil {
+		return err
+	}
+
+	return s.db.UpdateLoginAttempt(key, func(attempt *db.LoginAttempt) {
+		counter := &Counter{
+			Failures:      attempt.Failures,
+			LastFailureAt: attempt.LastFailureAt,
+			LockedUntil:   attempt.LockedUntil,
+		}
+
+		fn(counter)
+
+		attempt.Failures = counter.Failures
+		attempt.LastFailureAt = counter.LastFailureAt
+		attempt.LockedUntil = counter.LockedUntil
+	})
+}
+
+func (s *PostgresStore) cleanup(now, since time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastCleanup) < postgresCleanupInterval {
+		return nil
+	}
+
+	if err := s.db.DeleteStaleLoginAttempts(now, since); err != nil {
+		return err
+	}
+
+	s.lastCleanup = now
+	return nil
+}
+
+func (s *PostgresStore) Reset(key string) error {
+	return s.db.DeleteLoginAttempt(key)
+}
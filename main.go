@@ -11,6 +11,7 @@ import (
 
 	"github.com/anfimovoleh/ms-users/config"
 	"github.com/anfimovoleh/ms-users/server"
+	"github.com/anfimovoleh/ms-users/signing"
 )
 
 type App struct {
@@ -34,6 +35,10 @@ func (a *App) Start() error {
 		cfg,
 	)
 
+	if keystore := cfg.Authentication().Keystore(); keystore != nil {
+		go a.reloadKeys(keystore, cfg.Authentication().KeysReloadInterval)
+	}
+
 	serverHost := fmt.Sprintf("%s:%s", httpCfg.Host, httpCfg.Port)
 	a.log.With(zap.String("api", "start")).
 		Info(fmt.Sprintf("listenig addr =  %s", serverHost))
@@ -53,3 +58,18 @@ func (a *App) Start() error {
 
 	return nil
 }
+
+// reloadKeys picks up the keys rotated by other processes, so every replica
+// learns the new key and keeps the retired ones
+func (a *App) reloadKeys(keystore *signing.Keystore, interval time.Duration) {
+	log := a.log.With(zap.String("service", "keys-reload"))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := keystore.Reload(a.config.JWT()); err != nil {
+			log.With(zap.Error(err)).Error("failed to reload signing keys")
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// RecoveryCodesCount is the size of the set generated for the user
+	RecoveryCodesCount = 10
+	// recoveryCodeSize is the amount of random bytes in a code, 5 bytes give
+	// 8 base32 characters
+	recoveryCodeSize = 5
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateRecoveryCodes returns codes formatted as xxxx-xxxx for readability
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		raw := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, errors.Wrap(err, "failed to generate recovery code")
+		}
+
+		code := strings.ToLower(recoveryEncoding.EncodeToString(raw))
+		codes = append(codes, code[:4]+"-"+code[4:])
+	}
+
+	return codes, nil
+}
+
+// NormalizeRecoveryCode makes the comparison tolerant to case, dashes and spaces
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
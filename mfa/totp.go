@@ -0,0 +1,51 @@
+package mfa
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// period is the RFC 6238 time step in seconds
+const period = 30
+
+// GenerateTOTP creates a new secret, the returned key holds the base32 secret
+// and the otpauth:// URI for authenticator apps
+func GenerateTOTP(issuer, account string) (*otp.Key, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: account,
+		Period:      period,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate TOTP secret")
+	}
+
+	return key, nil
+}
+
+// ValidateTOTP checks the code against the current time step and one step of
+// clock drift in both directions. Steps not after lastStep are rejected, so a
+// code can't be replayed. The matched step is returned to be stored as lastStep.
+func ValidateTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	current := now.Unix() / period
+	for _, step := range []int64{current - 1, current, current + 1} {
+		if step <= lastStep {
+			continue
+		}
+
+		expected, err := totp.GenerateCode(secret, time.Unix(step*period, 0))
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
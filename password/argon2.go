@@ -0,0 +1,127 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2idID = "argon2id"
+
+// Argon2Params are the cost parameters of Argon2id, Memory is in KiB
+type Argon2Params struct {
+	Time       uint32
+	Memory     uint32
+	Threads    uint8
+	SaltLength uint32
+	KeyLength  uint32
+}
+
+// Argon2id is the default Hasher. Legacy bcrypt hashes are still verified
+// and always reported for rehash.
+type Argon2id struct {
+	params Argon2Params
+}
+
+func NewArgon2id(params Argon2Params) *Argon2id {
+	return &Argon2id{params: params}
+}
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(err, "failed to generate salt")
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.params.Time, a.params.Memory, a.params.Threads, a.params.KeyLength)
+
+	return encodeArgon2id(a.params, salt, key), nil
+}
+
+func (a *Argon2id) Verify(password, encoded string) (bool, bool, error) {
+	switch algorithm(encoded) {
+	case argon2idID:
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, false, err
+		}
+
+		actual := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(actual, key) != 1 {
+			return false, false, nil
+		}
+
+		outdated := params.Time != a.params.Time ||
+			params.Memory != a.params.Memory ||
+			params.Threads != a.params.Threads ||
+			params.SaltLength != a.params.SaltLength ||
+			params.KeyLength != a.params.KeyLength
+
+		return true, outdated, nil
+	case bcrypt2a, bcrypt2b, bcrypt2y:
+		ok, err := verifyBcrypt(password, encoded)
+		return ok, ok, err
+	default:
+		return false, false, ErrUnknownHash
+	}
+}
+
+// encodeArgon2id formats the hash as
+// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>
+func encodeArgon2id(params Argon2Params, salt, key []byte) string {
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idID, argon2.Version,
+		params.Memory, params.Time, params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func decodeArgon2id(encoded string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, errors.Wrap(err, "invalid argon2id version")
+	}
+
+	if version != argon2.Version {
+		return params, nil, nil, errors.Errorf("unsupported argon2 version %d", version)
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads)
+	if err != nil {
+		return params, nil, nil, errors.Wrap(err, "invalid argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errors.Wrap(err, "invalid argon2id salt")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, errors.Wrap(err, "invalid argon2id key")
+	}
+
+	//argon2.IDKey panics on zero time or threads, and an empty key would
+	//match any password
+	if params.Time == 0 || params.Threads == 0 || len(salt) == 0 || len(key) == 0 {
+		return params, nil, nil, errors.New("invalid argon2id parameters")
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
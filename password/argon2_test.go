@@ -0,0 +1,172 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testArgon2Params = Argon2Params{
+	Time:       1,
+	Memory:     64,
+	Threads:    1,
+	SaltLength: 16,
+	KeyLength:  32,
+}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	hasher := NewArgon2id(testArgon2Params)
+
+	encoded, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("hash %q is not in the PHC format", encoded)
+	}
+
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params != testArgon2Params {
+		t.Errorf("decoded params = %+v, want %+v", params, testArgon2Params)
+	}
+	if encodeArgon2id(params, salt, key) != encoded {
+		t.Error("decoded hash does not encode back to the same string")
+	}
+
+	ok, rehash, err := hasher.Verify("correct horse", encoded)
+	if err != nil || !ok || rehash {
+		t.Errorf("Verify(right password) = (%v, %v, %v), want (true, false, nil)", ok, rehash, err)
+	}
+
+	ok, _, err = hasher.Verify("wrong horse", encoded)
+	if err != nil || ok {
+		t.Errorf("Verify(wrong password) = (%v, %v), want (false, nil)", ok, err)
+	}
+
+	other, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == encoded {
+		t.Error("hashes of the same password share the salt")
+	}
+}
+
+func TestArgon2idRejectsMalformedHashes(t *testing.T) {
+	hasher := NewArgon2id(testArgon2Params)
+
+	encoded, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(encoded, "$")
+	salt, key := parts[4], parts[5]
+
+	cases := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "missing key", encoded: "$argon2id$v=19$m=64,t=1,p=1$" + salt},
+		{name: "extra part", encoded: encoded + "$extra"},
+		{name: "unsupported version", encoded: "$argon2id$v=16$m=64,t=1,p=1$" + salt + "$" + key},
+		{name: "garbage version", encoded: "$argon2id$v=x$m=64,t=1,p=1$" + salt + "$" + key},
+		{name: "garbage parameters", encoded: "$argon2id$v=19$m=64;t=1;p=1$" + salt + "$" + key},
+		{name: "zero time", encoded: "$argon2id$v=19$m=64,t=0,p=1$" + salt + "$" + key},
+		{name: "zero threads", encoded: "$argon2id$v=19$m=64,t=1,p=0$" + salt + "$" + key},
+		{name: "threads overflow", encoded: "$argon2id$v=19$m=64,t=1,p=256$" + salt + "$" + key},
+		{name: "invalid salt", encoded: "$argon2id$v=19$m=64,t=1,p=1$!!!$" + key},
+		{name: "invalid key", encoded: "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$!!!"},
+		{name: "empty key", encoded: "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$"},
+		{name: "empty salt", encoded: "$argon2id$v=19$m=64,t=1,p=1$$" + key},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ok, _, err := hasher.Verify("correct horse", c.encoded)
+			if err == nil || ok {
+				t.Errorf("Verify(%q) = (%v, %v), want an error", c.encoded, ok, err)
+			}
+		})
+	}
+
+	if _, _, err := hasher.Verify("correct horse", "plain text"); err != ErrUnknownHash {
+		t.Errorf("Verify(unknown format) error = %v, want ErrUnknownHash", err)
+	}
+}
+
+func TestArgon2idDetectsTamperedHashes(t *testing.T) {
+	hasher := NewArgon2id(testArgon2Params)
+
+	encoded, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the hash is recomputed with the changed cost, so it doesn't match
+	tampered := strings.Replace(encoded, "t=1", "t=2", 1)
+
+	ok, _, err := hasher.Verify("correct horse", tampered)
+	if err != nil || ok {
+		t.Errorf("Verify(tampered) = (%v, %v), want (false, nil)", ok, err)
+	}
+}
+
+func TestArgon2idRehash(t *testing.T) {
+	encoded, err := NewArgon2id(testArgon2Params).Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		modify func(p *Argon2Params)
+	}{
+		{name: "time", modify: func(p *Argon2Params) { p.Time = 2 }},
+		{name: "memory", modify: func(p *Argon2Params) { p.Memory = 128 }},
+		{name: "threads", modify: func(p *Argon2Params) { p.Threads = 2 }},
+		{name: "salt length", modify: func(p *Argon2Params) { p.SaltLength = 32 }},
+		{name: "key length", modify: func(p *Argon2Params) { p.KeyLength = 64 }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			params := testArgon2Params
+			c.modify(&params)
+
+			ok, rehash, err := NewArgon2id(params).Verify("correct horse", encoded)
+			if err != nil || !ok || !rehash {
+				t.Errorf("Verify() = (%v, %v, %v), want (true, true, nil)", ok, rehash, err)
+			}
+		})
+	}
+}
+
+func TestArgon2idVerifiesBcrypt(t *testing.T) {
+	hasher := NewArgon2id(testArgon2Params)
+
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		t.Run(prefix, func(t *testing.T) {
+			legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded := prefix + string(legacy[4:])
+
+			//legacy hashes are always replaced by Argon2id ones
+			ok, rehash, err := hasher.Verify("correct horse", encoded)
+			if err != nil || !ok || !rehash {
+				t.Errorf("Verify(right password) = (%v, %v, %v), want (true, true, nil)", ok, rehash, err)
+			}
+
+			ok, rehash, err = hasher.Verify("wrong horse", encoded)
+			if err != nil || ok || rehash {
+				t.Errorf("Verify(wrong password) = (%v, %v, %v), want (false, false, nil)", ok, rehash, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package password
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+// identifiers of the bcrypt hashes stored before Argon2id was introduced
+const (
+	bcrypt2a = "2a"
+	bcrypt2b = "2b"
+	bcrypt2y = "2y"
+)
+
+func verifyBcrypt(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,155 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// bloomMagic starts a serialized BloomFilter, followed by the amount of bits,
+// the amount of hash functions and the bit set, all big endian
+const bloomMagic = "MSUBLOOM"
+
+// BloomFilter is a compact in-memory form of a breached passwords dump. It has
+// no false negatives, while false positives occur at the rate it was built for.
+type BloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint64
+}
+
+// NewBloomFilter sizes the filter for the amount of hashes and the false
+// positive rate
+func NewBloomFilter(count uint64, falsePositiveRate float64) *BloomFilter {
+	if count == 0 {
+		count = 1
+	}
+
+	size := uint64(math.Ceil(-float64(count) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint64(math.Max(1, math.Round(float64(size)/float64(count)*math.Ln2)))
+
+	return &BloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// positions derives the bit indexes from the hash itself, SHA-1 is uniform
+// enough for the double hashing scheme
+func (b *BloomFilter) positions(hash [sha1.Size]byte, fn func(uint64)) {
+	first := binary.BigEndian.Uint64(hash[0:8])
+	second := binary.BigEndian.Uint64(hash[8:16]) | 1
+
+	for i := uint64(0); i < b.hashes; i++ {
+		fn((first + i*second) % b.size)
+	}
+}
+
+func (b *BloomFilter) AddHash(hash [sha1.Size]byte) {
+	b.positions(hash, func(position uint64) {
+		b.bits[position/64] |= 1 << (position % 64)
+	})
+}
+
+func (b *BloomFilter) ContainsHash(hash [sha1.Size]byte) (bool, error) {
+	contains := true
+	b.positions(hash, func(position uint64) {
+		if b.bits[position/64]&(1<<(position%64)) == 0 {
+			contains = false
+		}
+	})
+
+	return contains, nil
+}
+
+func (b *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	writer := bufio.NewWriter(w)
+	if _, err := writer.WriteString(bloomMagic); err != nil {
+		return 0, err
+	}
+
+	var word [8]byte
+	for _, value := range [2]uint64{b.size, b.hashes} {
+		binary.BigEndian.PutUint64(word[:], value)
+		if _, err := writer.Write(word[:]); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, value := range b.bits {
+		binary.BigEndian.PutUint64(word[:], value)
+		if _, err := writer.Write(word[:]); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return 0, err
+	}
+
+	return int64(len(bloomMagic) + 8*(2+len(b.bits))), nil
+}
+
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	magic := make([]byte, len(bloomMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != bloomMagic {
+		return nil, errors.New("not a bloom filter file")
+	}
+
+	var header [2]uint64
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, errors.Wrap(err, "failed to read bloom filter header")
+	}
+
+	filter := &BloomFilter{
+		bits:   make([]uint64, (header[0]+63)/64),
+		size:   header[0],
+		hashes: header[1],
+	}
+
+	if filter.size == 0 || filter.hashes == 0 {
+		return nil, errors.New("invalid bloom filter header")
+	}
+
+	//the bit set is read word by word, it may be too big to be buffered twice
+	reader := bufio.NewReader(r)
+	var word [8]byte
+	for i := range filter.bits {
+		if _, err := io.ReadFull(reader, word[:]); err != nil {
+			return nil, errors.Wrap(err, "failed to read bloom filter")
+		}
+		filter.bits[i] = binary.BigEndian.Uint64(word[:])
+	}
+
+	return filter, nil
+}
+
+// BuildBloomFilter reads a dump with a HASH or HASH:COUNT line per password
+func BuildBloomFilter(dump io.Reader, count uint64, falsePositiveRate float64) (*BloomFilter, error) {
+	filter := NewBloomFilter(count, falsePositiveRate)
+
+	scanner := bufio.NewScanner(dump)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		hash, err := ParseHashLine(scanner.Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		filter.AddHash(hash)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read dump")
+	}
+
+	return filter, nil
+}
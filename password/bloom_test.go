@@ -0,0 +1,113 @@
+package password
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testDump returns a dump of the passwords formatted as HASH:COUNT lines
+func testDump(passwords []string) string {
+	var dump strings.Builder
+	for i, password := range passwords {
+		hash := sha1.Sum([]byte(password))
+		fmt.Fprintf(&dump, "%s:%d\r\n", strings.ToUpper(hex.EncodeToString(hash[:])), i+1)
+	}
+
+	return dump.String()
+}
+
+func testPasswords(prefix string, count int) []string {
+	passwords := make([]string, count)
+	for i := range passwords {
+		passwords[i] = fmt.Sprintf("%s-%d", prefix, i)
+	}
+
+	return passwords
+}
+
+func TestBloomFilterRoundTrip(t *testing.T) {
+	breached := testPasswords("breached", 1000)
+
+	//an empty line, e.g. at the end of the dump, is skipped
+	filter, err := BuildBloomFilter(strings.NewReader(testDump(breached)+"\n"), uint64(len(breached)), 0.001)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var file bytes.Buffer
+	written, err := filter.WriteTo(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(file.Len()) {
+		t.Errorf("WriteTo reported %d bytes, wrote %d", written, file.Len())
+	}
+
+	read, err := ReadBloomFilter(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.size != filter.size || read.hashes != filter.hashes {
+		t.Errorf("read size %d and hashes %d, want %d and %d", read.size, read.hashes, filter.size, filter.hashes)
+	}
+
+	//a Bloom filter has no false negatives
+	for _, password := range breached {
+		contains, err := Breached(read, password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !contains {
+			t.Fatalf("breached password %q is not in the filter", password)
+		}
+	}
+
+	falsePositives := 0
+	for _, password := range testPasswords("safe", 10000) {
+		contains, err := Breached(read, password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contains {
+			falsePositives++
+		}
+	}
+
+	//0.1% is expected, ten times that would mean the filter is mis-sized
+	if falsePositives > 100 {
+		t.Errorf("%d false positives out of 10000, want about 10", falsePositives)
+	}
+}
+
+func TestReadBloomFilterRejectsInvalidFiles(t *testing.T) {
+	var valid bytes.Buffer
+	if _, err := NewBloomFilter(10, 0.01).WriteTo(&valid); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string][]byte{
+		"empty":            {},
+		"other magic":      append([]byte("NOTBLOOM"), valid.Bytes()[len(bloomMagic):]...),
+		"truncated header": valid.Bytes()[:len(bloomMagic)+4],
+		"zero size":        append([]byte(bloomMagic), make([]byte, 16)...),
+		"truncated bits":   valid.Bytes()[:valid.Len()-1],
+	}
+
+	for name, file := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadBloomFilter(bytes.NewReader(file)); err == nil {
+				t.Error("invalid bloom filter accepted")
+			}
+		})
+	}
+}
+
+func TestBuildBloomFilterRejectsInvalidLines(t *testing.T) {
+	if _, err := BuildBloomFilter(strings.NewReader("not a hash:1\n"), 1, 0.01); err == nil {
+		t.Error("dump with an invalid hash accepted")
+	}
+}
@@ -0,0 +1,68 @@
+package password
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// BreachedCorpus tells whether a password appears in known data breaches.
+// Passwords are looked up by SHA-1 as in the Have I Been Pwned dumps.
+type BreachedCorpus interface {
+	ContainsHash(hash [sha1.Size]byte) (bool, error)
+}
+
+// Breached looks the password up in the corpus
+func Breached(corpus BreachedCorpus, password string) (bool, error) {
+	return corpus.ContainsHash(sha1.Sum([]byte(password)))
+}
+
+// OpenBreachedCorpus loads either a Bloom filter built by BuildBloomFilter or
+// a hash file sorted by hash, the format is detected from the file header
+func OpenBreachedCorpus(path string) (BreachedCorpus, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open breached passwords file")
+	}
+
+	header := make([]byte, len(bloomMagic))
+	if _, err := io.ReadFull(file, header); err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		file.Close()
+		return nil, errors.Wrap(err, "failed to read breached passwords file")
+	}
+
+	if !bytes.Equal(header, []byte(bloomMagic)) {
+		return NewHashFile(file)
+	}
+
+	defer file.Close()
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "failed to read breached passwords file")
+	}
+
+	return ReadBloomFilter(file)
+}
+
+// ParseHashLine parses a line of the dump formatted as HASH or HASH:COUNT
+func ParseHashLine(line []byte) ([sha1.Size]byte, error) {
+	var hash [sha1.Size]byte
+
+	line = bytes.TrimSpace(line)
+	if i := bytes.IndexByte(line, ':'); i >= 0 {
+		line = line[:i]
+	}
+
+	if len(line) != hex.EncodedLen(sha1.Size) {
+		return hash, errors.Errorf("invalid hash %q", line)
+	}
+
+	if _, err := hex.Decode(hash[:], line); err != nil {
+		return hash, errors.Wrapf(err, "invalid hash %q", line)
+	}
+
+	return hash, nil
+}
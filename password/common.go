@@ -0,0 +1,15 @@
+package password
+
+// DefaultCommonPasswords is used when no common passwords file is configured,
+// it holds the most frequent passwords of the public breach compilations
+var DefaultCommonPasswords = []string{
+	"123456", "123456789", "12345678", "1234567890", "12345", "1234567",
+	"111111", "000000", "123123", "654321", "666666", "121212", "112233",
+	"123321", "7777777", "987654321", "1q2w3e4r", "1q2w3e4r5t", "1qaz2wsx",
+	"qwerty", "qwerty123", "qwertyuiop", "asdfghjkl", "zxcvbnm", "abc123",
+	"password", "password1", "password123", "passw0rd", "p@ssw0rd",
+	"iloveyou", "admin", "admin123", "welcome", "welcome1", "letmein",
+	"monkey", "dragon", "football", "baseball", "sunshine", "princess",
+	"superman", "batman", "master", "shadow", "michael", "charlie",
+	"trustno1", "starwars", "whatever", "freedom", "hello123", "changeme",
+}
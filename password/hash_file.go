@@ -0,0 +1,99 @@
+package password
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// maxHashLineLength bounds a HASH:COUNT line of the dump
+const maxHashLineLength = 128
+
+// HashFile is a dump with one HASH:COUNT line per password sorted by hash,
+// it is binary searched on disk so it doesn't have to fit in memory
+type HashFile struct {
+	file *os.File
+	size int64
+}
+
+func NewHashFile(file *os.File) (*HashFile, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stat hash file")
+	}
+
+	return &HashFile{file: file, size: info.Size()}, nil
+}
+
+func (f *HashFile) ContainsHash(hash [sha1.Size]byte) (bool, error) {
+	//the lines starting in [low, high) are still candidates
+	low, high := int64(0), f.size
+	for low < high {
+		middle := low + (high-low)/2
+
+		start, line, err := f.lineFrom(middle)
+		if err != nil {
+			return false, err
+		}
+
+		if line == nil || start >= high {
+			high = middle
+			continue
+		}
+
+		current, err := ParseHashLine(line)
+		if err != nil {
+			return false, err
+		}
+
+		switch bytes.Compare(current[:], hash[:]) {
+		case 0:
+			return true, nil
+		case -1:
+			low = start + int64(len(line)) + 1
+		default:
+			high = middle
+		}
+	}
+
+	return false, nil
+}
+
+// lineFrom returns the first line starting at offset or later
+func (f *HashFile) lineFrom(offset int64) (int64, []byte, error) {
+	//read the previous byte as well to know if a line starts right at offset
+	from := offset
+	if from > 0 {
+		from--
+	}
+
+	buffer := make([]byte, 2*maxHashLineLength)
+	n, err := f.file.ReadAt(buffer, from)
+	if err != nil && err != io.EOF {
+		return 0, nil, errors.Wrap(err, "failed to read hash file")
+	}
+	buffer = buffer[:n]
+
+	start := 0
+	if offset > 0 {
+		newline := bytes.IndexByte(buffer, '\n')
+		if newline < 0 {
+			return 0, nil, nil
+		}
+		start = newline + 1
+	}
+
+	if start >= len(buffer) {
+		return 0, nil, nil
+	}
+
+	line := buffer[start:]
+	if end := bytes.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+
+	return from + int64(start), line, nil
+}
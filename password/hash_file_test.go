@@ -0,0 +1,124 @@
+package password
+
+import (
+	"crypto/sha1"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeHashFile writes the dump and opens it the way the server does
+func writeHashFile(t *testing.T, dump string) BreachedCorpus {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(dump), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	corpus, err := OpenBreachedCorpus(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := corpus.(*HashFile); !ok {
+		t.Fatalf("opened %T, want a hash file", corpus)
+	}
+
+	t.Cleanup(func() {
+		corpus.(*HashFile).file.Close()
+	})
+
+	return corpus
+}
+
+// sortedByHash returns the passwords in the order of the hash file
+func sortedByHash(passwords []string) []string {
+	sorted := append([]string(nil), passwords...)
+	sort.Slice(sorted, func(i, j int) bool {
+		first, second := sha1.Sum([]byte(sorted[i])), sha1.Sum([]byte(sorted[j]))
+		return string(first[:]) < string(second[:])
+	})
+
+	return sorted
+}
+
+func TestHashFileContainsHash(t *testing.T) {
+	for _, count := range []int{0, 1, 2, 3, 10, 1000} {
+		passwords := sortedByHash(testPasswords("breached", count))
+		corpus := writeHashFile(t, testDump(passwords))
+
+		for i, password := range passwords {
+			contains, err := Breached(corpus, password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !contains {
+				t.Errorf("%d records: record %d is not found", count, i)
+			}
+		}
+
+		for _, password := range testPasswords("safe", 100) {
+			contains, err := Breached(corpus, password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contains {
+				t.Errorf("%d records: absent password %q is found", count, password)
+			}
+		}
+	}
+}
+
+func TestHashFileWithoutTrailingNewline(t *testing.T) {
+	for _, count := range []int{1, 2, 3} {
+		passwords := sortedByHash(testPasswords("breached", count))
+		dump := testDump(passwords)
+		corpus := writeHashFile(t, strings.TrimSuffix(dump, "\r\n"))
+
+		for i, password := range passwords {
+			contains, err := Breached(corpus, password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !contains {
+				t.Errorf("%d records: record %d is not found", count, i)
+			}
+		}
+	}
+}
+
+func TestHashFileContainsHashBounds(t *testing.T) {
+	passwords := sortedByHash(testPasswords("breached", 100))
+	corpus := writeHashFile(t, testDump(passwords))
+
+	lowest := [sha1.Size]byte{}
+	highest := [sha1.Size]byte{}
+	for i := range highest {
+		highest[i] = 0xff
+	}
+
+	cases := []struct {
+		name string
+		hash [sha1.Size]byte
+		want bool
+	}{
+		{name: "first record", hash: sha1.Sum([]byte(passwords[0])), want: true},
+		{name: "last record", hash: sha1.Sum([]byte(passwords[len(passwords)-1])), want: true},
+		{name: "before the first record", hash: lowest, want: false},
+		{name: "after the last record", hash: highest, want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			contains, err := corpus.ContainsHash(c.hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if contains != c.want {
+				t.Errorf("ContainsHash = %v, want %v", contains, c.want)
+			}
+		})
+	}
+}
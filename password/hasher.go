@@ -0,0 +1,48 @@
+package password
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownHash is returned for a stored hash of an unsupported algorithm
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Hasher produces self-describing hashes in the PHC string format, so the
+// algorithm and its parameters can change without breaking stored passwords.
+type Hasher interface {
+	// Hash returns the encoded hash of the password
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the encoded hash and
+	// whether the hash should be replaced by a fresh Hash result, because
+	// it was made by another algorithm or with outdated parameters
+	Verify(password, encoded string) (ok bool, rehash bool, err error)
+}
+
+// algorithm returns the identifier of the encoded hash, e.g. argon2id or 2a
+func algorithm(encoded string) string {
+	parts := strings.SplitN(encoded, "$", 3)
+	if len(parts) < 3 || parts[0] != "" {
+		return ""
+	}
+
+	return parts[1]
+}
+
+// Reused reports whether the password matches any of the encoded hashes,
+// e.g. the current password of a user and the ones kept in the history
+func Reused(hasher Hasher, password string, hashes []string) (bool, error) {
+	for _, encoded := range hashes {
+		ok, _, err := hasher.Verify(password, encoded)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to verify password")
+		}
+
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
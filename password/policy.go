@@ -0,0 +1,182 @@
+package password
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Violation is a machine-readable code of a failed policy rule
+type Violation string
+
+const (
+	ViolationTooShort         Violation = "too_short"
+	ViolationTooLong          Violation = "too_long"
+	ViolationMissingLowercase Violation = "missing_lowercase"
+	ViolationMissingUppercase Violation = "missing_uppercase"
+	ViolationMissingDigit     Violation = "missing_digit"
+	ViolationMissingSymbol    Violation = "missing_symbol"
+	ViolationContainsEmail    Violation = "contains_email"
+	ViolationContainsName     Violation = "contains_name"
+	ViolationCommon           Violation = "common_password"
+	ViolationBreached         Violation = "breached_password"
+	ViolationReused           Violation = "reused_password"
+)
+
+// minPersonalInfoLength skips too short email local-parts and name parts,
+// otherwise e.g. the name "Al" would forbid a lot of passwords
+const minPersonalInfoLength = 3
+
+// Policy is the set of rules a new password has to satisfy
+type Policy struct {
+	MinLength          int  `json:"min_length"`
+	MaxLength          int  `json:"max_length"`
+	RequireLowercase   bool `json:"require_lowercase"`
+	RequireUppercase   bool `json:"require_uppercase"`
+	RequireDigit       bool `json:"require_digit"`
+	RequireSymbol      bool `json:"require_symbol"`
+	RejectPersonalInfo bool `json:"reject_personal_info"`
+
+	common   map[string]struct{}
+	breached BreachedCorpus
+}
+
+// Owner is the personal information a password must not contain
+type Owner struct {
+	Email string
+	Name  string
+}
+
+// SetCommonPasswords replaces the list of rejected passwords, the comparison
+// is case-insensitive
+func (p *Policy) SetCommonPasswords(passwords []string) {
+	p.common = make(map[string]struct{}, len(passwords))
+	for _, password := range passwords {
+		p.common[strings.ToLower(password)] = struct{}{}
+	}
+}
+
+// LoadCommonPasswords reads the list of rejected passwords from a file with
+// one password per line
+func (p *Policy) LoadCommonPasswords(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open common passwords file")
+	}
+	defer file.Close()
+
+	var passwords []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			passwords = append(passwords, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to read common passwords file")
+	}
+
+	p.SetCommonPasswords(passwords)
+	return nil
+}
+
+// SetBreachedCorpus makes the passwords of known data breaches rejected
+func (p *Policy) SetBreachedCorpus(corpus BreachedCorpus) {
+	p.breached = corpus
+}
+
+// Check returns every rule the password violates, none means it is accepted
+func (p *Policy) Check(password string, owner Owner) ([]Violation, error) {
+	var violations []Violation
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		violations = append(violations, ViolationTooShort)
+	}
+
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, ViolationTooLong)
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsLower(char):
+			lower = true
+		case unicode.IsUpper(char):
+			upper = true
+		case unicode.IsDigit(char):
+			digit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char) || unicode.IsSpace(char):
+			symbol = true
+		}
+	}
+
+	if p.RequireLowercase && !lower {
+		violations = append(violations, ViolationMissingLowercase)
+	}
+
+	if p.RequireUppercase && !upper {
+		violations = append(violations, ViolationMissingUppercase)
+	}
+
+	if p.RequireDigit && !digit {
+		violations = append(violations, ViolationMissingDigit)
+	}
+
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, ViolationMissingSymbol)
+	}
+
+	normalized := strings.ToLower(password)
+	if p.RejectPersonalInfo {
+		if containsEmail(normalized, owner.Email) {
+			violations = append(violations, ViolationContainsEmail)
+		}
+
+		if containsName(normalized, owner.Name) {
+			violations = append(violations, ViolationContainsName)
+		}
+	}
+
+	if _, ok := p.common[normalized]; ok {
+		violations = append(violations, ViolationCommon)
+	}
+
+	if p.breached != nil {
+		breached, err := Breached(p.breached, password)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check breached passwords")
+		}
+
+		if breached {
+			violations = append(violations, ViolationBreached)
+		}
+	}
+
+	return violations, nil
+}
+
+func containsEmail(password, email string) bool {
+	local := strings.ToLower(email)
+	if at := strings.LastIndex(local, "@"); at >= 0 {
+		local = local[:at]
+	}
+
+	return utf8.RuneCountInString(local) >= minPersonalInfoLength && strings.Contains(password, local)
+}
+
+func containsName(password, name string) bool {
+	for _, part := range strings.Fields(strings.ToLower(name)) {
+		if utf8.RuneCountInString(part) >= minPersonalInfoLength && strings.Contains(password, part) {
+			return true
+		}
+	}
+
+	return false
+}
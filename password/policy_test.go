@@ -0,0 +1,233 @@
+package password
+
+import (
+	"crypto/sha1"
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeCorpus is a breached corpus of the listed passwords
+type fakeCorpus map[[sha1.Size]byte]struct{}
+
+func newFakeCorpus(passwords ...string) fakeCorpus {
+	corpus := fakeCorpus{}
+	for _, password := range passwords {
+		corpus[sha1.Sum([]byte(password))] = struct{}{}
+	}
+
+	return corpus
+}
+
+func (c fakeCorpus) ContainsHash(hash [sha1.Size]byte) (bool, error) {
+	_, ok := c[hash]
+	return ok, nil
+}
+
+func TestPolicyCheck(t *testing.T) {
+	owner := Owner{Email: "skipper.jones@example.com", Name: "Johnny Al Doe"}
+
+	cases := []struct {
+		name     string
+		policy   Policy
+		password string
+		want     []Violation
+	}{
+		{
+			name:     "accepted",
+			policy:   Policy{MinLength: 8, MaxLength: 16},
+			password: "kettle-yellow-42",
+		},
+		{
+			name:     "too short",
+			policy:   Policy{MinLength: 8},
+			password: "kettle",
+			want:     []Violation{ViolationTooShort},
+		},
+		{
+			//the length is counted in characters, not bytes
+			name:     "multi-byte characters",
+			policy:   Policy{MinLength: 4, MaxLength: 4},
+			password: "ключ",
+		},
+		{
+			name:     "too long",
+			policy:   Policy{MaxLength: 8},
+			password: "kettle-yellow",
+			want:     []Violation{ViolationTooLong},
+		},
+		{
+			name:     "missing lowercase",
+			policy:   Policy{RequireLowercase: true},
+			password: "KETTLE-42",
+			want:     []Violation{ViolationMissingLowercase},
+		},
+		{
+			name:     "missing uppercase",
+			policy:   Policy{RequireUppercase: true},
+			password: "kettle-42",
+			want:     []Violation{ViolationMissingUppercase},
+		},
+		{
+			name:     "missing digit",
+			policy:   Policy{RequireDigit: true},
+			password: "kettle-yellow",
+			want:     []Violation{ViolationMissingDigit},
+		},
+		{
+			name:     "missing symbol",
+			policy:   Policy{RequireSymbol: true},
+			password: "kettle42",
+			want:     []Violation{ViolationMissingSymbol},
+		},
+		{
+			name:     "space is a symbol",
+			policy:   Policy{RequireSymbol: true},
+			password: "kettle 42",
+		},
+		{
+			name: "every character class",
+			policy: Policy{
+				RequireLowercase: true,
+				RequireUppercase: true,
+				RequireDigit:     true,
+				RequireSymbol:    true,
+			},
+			password: "Kettle-42",
+		},
+		{
+			name:     "contains email",
+			policy:   Policy{RejectPersonalInfo: true},
+			password: "my-SKIPPER.JONES-pass",
+			want:     []Violation{ViolationContainsEmail},
+		},
+		{
+			name:     "contains name",
+			policy:   Policy{RejectPersonalInfo: true},
+			password: "kettle-doe-42",
+			want:     []Violation{ViolationContainsName},
+		},
+		{
+			//name parts shorter than minPersonalInfoLength are ignored
+			name:     "contains short name part",
+			policy:   Policy{RejectPersonalInfo: true},
+			password: "kettle-al-42",
+		},
+		{
+			name:     "personal info allowed",
+			policy:   Policy{},
+			password: "skipper.jones-doe",
+		},
+		{
+			name:     "every violation at once",
+			policy:   Policy{MinLength: 12, RequireUppercase: true, RequireDigit: true, RequireSymbol: true, RejectPersonalInfo: true},
+			password: "johnnydoe",
+			want: []Violation{
+				ViolationTooShort,
+				ViolationMissingUppercase,
+				ViolationMissingDigit,
+				ViolationMissingSymbol,
+				ViolationContainsName,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			violations, err := c.policy.Check(c.password, owner)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(violations, c.want) {
+				t.Errorf("Check(%q) = %v, want %v", c.password, violations, c.want)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckCommonPasswords(t *testing.T) {
+	policy := &Policy{}
+	policy.SetCommonPasswords([]string{"Password1"})
+
+	violations, err := policy.Check("PASSWORD1", Owner{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(violations, []Violation{ViolationCommon}) {
+		t.Errorf("Check(common password) = %v, want %v", violations, []Violation{ViolationCommon})
+	}
+}
+
+func TestPolicyCheckBreachedPasswords(t *testing.T) {
+	policy := &Policy{}
+	policy.SetBreachedCorpus(newFakeCorpus("hunter2"))
+
+	violations, err := policy.Check("hunter2", Owner{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(violations, []Violation{ViolationBreached}) {
+		t.Errorf("Check(breached password) = %v, want %v", violations, []Violation{ViolationBreached})
+	}
+
+	//the corpus is looked up by the exact password
+	violations, err = policy.Check("Hunter2", Owner{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(violations) != 0 {
+		t.Errorf("Check(other password) = %v, want none", violations)
+	}
+}
+
+func TestReused(t *testing.T) {
+	hasher := NewArgon2id(testArgon2Params)
+
+	var history []string
+	for _, previous := range []string{"kettle-yellow-1", "kettle-yellow-2"} {
+		encoded, err := hasher.Hash(previous)
+		if err != nil {
+			t.Fatal(err)
+		}
+		history = append(history, encoded)
+	}
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("kettle-yellow-0"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history = append(history, string(legacy))
+
+	cases := []struct {
+		password string
+		want     bool
+	}{
+		{password: "kettle-yellow-1", want: true},
+		{password: "kettle-yellow-2", want: true},
+		{password: "kettle-yellow-0", want: true},
+		{password: "kettle-yellow-3", want: false},
+		{password: "Kettle-yellow-1", want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.password, func(t *testing.T) {
+			reused, err := Reused(hasher, c.password, history)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if reused != c.want {
+				t.Errorf("Reused(%q) = %v, want %v", c.password, reused, c.want)
+			}
+		})
+	}
+
+	if _, err := Reused(hasher, "kettle-yellow-1", []string{"$unknown$hash"}); err == nil {
+		t.Error("Reused accepted a hash of an unknown algorithm")
+	}
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// memorySweepSize is the amount of keys after which the stale ones are dropped
+const memorySweepSize = 10000
+
+type memoryCounter struct {
+	start    time.Time
+	window   time.Duration
+	current  int
+	previous int
+}
+
+// MemoryStore keeps the counters in the process, it fits a single replica
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*memoryCounter
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]*memoryCounter)}
+}
+
+func (s *MemoryStore) Increment(key string, start time.Time, window time.Duration) (int, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.counters) >= memorySweepSize {
+		s.sweep(start)
+	}
+
+	counter, ok := s.counters[key]
+	switch {
+	case !ok:
+		counter = &memoryCounter{start: start, window: window}
+		s.counters[key] = counter
+	case counter.start.Equal(start):
+	case counter.start.Add(window).Equal(start):
+		counter.previous, counter.current, counter.start = counter.current, 0, start
+	default:
+		counter.previous, counter.current, counter.start = 0, 0, start
+	}
+
+	counter.window = window
+	counter.current++
+
+	return counter.current, counter.previous, nil
+}
+
+// sweep drops the counters which can't affect the sliding window anymore
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, counter := range s.counters {
+		if counter.start.Add(2 * counter.window).Before(now) {
+			delete(s.counters, key)
+		}
+	}
+}
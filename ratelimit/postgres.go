@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+// postgresCleanupInterval limits how often the expired counters are dropped
+const postgresCleanupInterval = time.Minute
+
+// PostgresStore keeps the counters in the rate_limits table, so they are
+// shared by every replica
+type PostgresStore struct {
+	db *db.DB
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewPostgresStore(db *db.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Increment(key string, start time.Time, window time.Duration) (int, int, error) {
+	if err := s.cleanup(time.Now()); err != nil {
+		return 0, 0, err
+	}
+
+	return s.db.IncrementRateLimit(key, start, window)
+}
+
+func (s *PostgresStore) cleanup(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastCleanup) < postgresCleanupInterval {
+		return nil
+	}
+
+	if err := s.db.DeleteExpiredRateLimits(now); err != nil {
+		return err
+	}
+
+	s.lastCleanup = now
+	return nil
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Limit allows Requests per Window
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// ParseLimit parses limits formatted as <requests>/<window>, e.g. 10/1h
+func ParseLimit(value string) (Limit, error) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return Limit{}, errors.Errorf("invalid rate limit %q", value)
+	}
+
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil || requests <= 0 {
+		return Limit{}, errors.Errorf("invalid rate limit requests %q", parts[0])
+	}
+
+	window, err := time.ParseDuration(parts[1])
+	if err != nil || window <= 0 {
+		return Limit{}, errors.Errorf("invalid rate limit window %q", parts[1])
+	}
+
+	return Limit{Requests: requests, Window: window}, nil
+}
+
+// Store counts the hits of a key per fixed window
+type Store interface {
+	// Increment counts a hit in the window starting at start and returns the
+	// hits of this window and of the previous one
+	Increment(key string, start time.Time, window time.Duration) (current int, previous int, err error)
+}
+
+// Result describes the state of the limit after a request
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the current window ends for an allowed
+	// request and the time until the next request is allowed for a
+	// rejected one
+	Reset time.Duration
+}
+
+// Limiter implements the sliding window counter: the hits of the previous
+// window are weighted by the part of it still inside the sliding window
+type Limiter struct {
+	store Store
+}
+
+func New(store Store) *Limiter {
+	return &Limiter{store: store}
+}
+
+// Allow counts the request, rejected requests are counted as well, so
+// a client ignoring Retry-After stays limited
+func (l *Limiter) Allow(key string, limit Limit, now time.Time) (*Result, error) {
+	start := now.Truncate(limit.Window)
+	elapsed := now.Sub(start)
+
+	current, previous, err := l.store.Increment(key, start, limit.Window)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to count request")
+	}
+
+	weight := 1 - float64(elapsed)/float64(limit.Window)
+	estimate := float64(previous)*weight + float64(current)
+
+	result := &Result{
+		Allowed:   estimate <= float64(limit.Requests),
+		Limit:     limit.Requests,
+		Remaining: int(math.Max(0, float64(limit.Requests)-math.Ceil(estimate))),
+	}
+
+	if result.Allowed {
+		result.Reset = limit.Window - elapsed
+		return result, nil
+	}
+
+	result.Reset = retryAfter(limit, elapsed, current, previous)
+	return result, nil
+}
+
+// retryAfter solves the estimate of a future moment for the limit, taking
+// into account the hit of the retried request itself. The moment is rounded
+// up, so the retry does not land before it.
+func retryAfter(limit Limit, elapsed time.Duration, current, previous int) time.Duration {
+	window := float64(limit.Window)
+	requests := float64(limit.Requests)
+
+	//enough of the previous window slides out before this one ends
+	if current < limit.Requests && previous > 0 {
+		at := window * (1 - (requests-float64(current)-1)/float64(previous))
+		return time.Duration(math.Ceil(at)) - elapsed
+	}
+
+	//this window becomes the previous one and has to slide out partially
+	at := window * (1 - (requests-1)/float64(current))
+	return limit.Window - elapsed + time.Duration(math.Ceil(at))
+}
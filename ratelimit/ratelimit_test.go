@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	limit := Limit{Requests: 10, Window: time.Minute}
+
+	cases := []struct {
+		name     string
+		elapsed  time.Duration
+		current  int
+		previous int
+		want     time.Duration
+	}{
+		{
+			//20*(1-48/60) + 5 + 1 = 10 at 48s of this window
+			name:     "previous window slides out",
+			elapsed:  30 * time.Second,
+			current:  5,
+			previous: 20,
+			want:     18 * time.Second,
+		},
+		{
+			//12*(1-15/60) + 1 = 10 at 15s of the next window
+			name:    "current window slides out",
+			elapsed: 30 * time.Second,
+			current: 12,
+			want:    45 * time.Second,
+		},
+		{
+			//10*(1-6/60) + 1 = 10 at 6s of the next window, the previous
+			//window has slid out by then
+			name:     "current window is full",
+			elapsed:  30 * time.Second,
+			current:  10,
+			previous: 10,
+			want:     36 * time.Second,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := retryAfter(limit, c.elapsed, c.current, c.previous)
+			if got != c.want {
+				t.Errorf("retryAfter() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestAllow(t *testing.T) {
+	limit := Limit{Requests: 3, Window: time.Minute}
+	start := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(NewMemoryStore())
+
+	for i := 0; i < limit.Requests; i++ {
+		result, err := limiter.Allow("key", limit, start.Add(10*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !result.Allowed {
+			t.Fatalf("request %d rejected", i+1)
+		}
+		if result.Remaining != limit.Requests-i-1 {
+			t.Errorf("request %d: remaining = %d, want %d", i+1, result.Remaining, limit.Requests-i-1)
+		}
+		if result.Reset != 50*time.Second {
+			t.Errorf("request %d: reset = %v, want the end of the window", i+1, result.Reset)
+		}
+	}
+
+	rejected, err := limiter.Allow("key", limit, start.Add(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rejected.Allowed {
+		t.Fatal("request over the limit allowed")
+	}
+	if rejected.Remaining != 0 {
+		t.Errorf("remaining = %d, want 0", rejected.Remaining)
+	}
+
+	//other keys are counted separately
+	other, err := limiter.Allow("other", limit, start.Add(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !other.Allowed {
+		t.Error("request of another key rejected")
+	}
+}
+
+func TestAllowAfterRetry(t *testing.T) {
+	limit := Limit{Requests: 10, Window: time.Minute}
+	start := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		previous int
+		current  int
+		at       time.Duration
+	}{
+		{name: "current window", current: 11, at: 5 * time.Second},
+		{name: "previous window", previous: 20, current: 1, at: 30 * time.Second},
+		{name: "both windows", previous: 10, current: 6, at: 30 * time.Second},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			limiter := New(NewMemoryStore())
+
+			for i := 0; i < c.previous; i++ {
+				if _, err := limiter.Allow("key", limit, start.Add(-time.Second)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var result *Result
+			for i := 0; i < c.current; i++ {
+				var err error
+				result, err = limiter.Allow("key", limit, start.Add(c.at))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if result.Allowed {
+				t.Fatal("request over the limit allowed")
+			}
+
+			//a client honouring Retry-After, which is sent in whole seconds,
+			//is allowed on the first retry
+			wait := time.Duration(math.Ceil(result.Reset.Seconds())) * time.Second
+			retry, err := limiter.Allow("key", limit, start.Add(c.at+wait))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !retry.Allowed {
+				t.Errorf("retry after %v rejected", wait)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+
+	"github.com/anfimovoleh/ms-users/utils"
+)
+
+// AdminOnly rejects requests of users who are not admins.
+// It has to be preceded by the Authenticator middleware.
+func AdminOnly(log *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, _, err := utils.User(r.Context(), DB(r))
+			if err != nil {
+				log.With(
+					zap.Uint64("user_id", Session(r).UserID),
+					zap.Error(err),
+				).Error("failed to get session user")
+				httperr.InternalServerError(w)
+				return
+			}
+
+			if !user.IsAdmin {
+				httperr.ErrResponse(w, http.StatusForbidden, ErrAdminRequired)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
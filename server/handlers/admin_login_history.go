@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+const (
+	defaultAdminLoginHistoryLimit = 100
+	maxAdminLoginHistoryLimit     = 1000
+)
+
+// AdminLoginHistoryRequest filters the login history of all users, Since
+// and Until are RFC 3339 timestamps
+type AdminLoginHistoryRequest struct {
+	UserID *uint64        `json:"user_id"`
+	Email  string         `json:"email"`
+	IP     string         `json:"ip"`
+	Result db.LoginResult `json:"result"`
+	Since  *time.Time     `json:"since"`
+	Until  *time.Time     `json:"until"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// NewAdminLoginHistoryRequest reads the request from the query string
+func NewAdminLoginHistoryRequest(r *http.Request) (*AdminLoginHistoryRequest, error) {
+	query := r.URL.Query()
+	request := &AdminLoginHistoryRequest{
+		Email:  query.Get("email"),
+		IP:     query.Get("ip"),
+		Result: db.LoginResult(query.Get("result")),
+		Limit:  defaultAdminLoginHistoryLimit,
+	}
+
+	errs := validation.Errors{}
+
+	if userID := query.Get("user_id"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 64)
+		if err != nil {
+			errs["user_id"] = is.ErrInt
+		}
+		request.UserID = &id
+	}
+
+	if since := query.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			errs["since"] = validation.ErrDateInvalid
+		}
+		request.Since = &t
+	}
+
+	if until := query.Get("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			errs["until"] = validation.ErrDateInvalid
+		}
+		request.Until = &t
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if request.Limit, err = strconv.Atoi(limit); err != nil {
+			errs["limit"] = is.ErrInt
+		}
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		var err error
+		if request.Offset, err = strconv.Atoi(offset); err != nil {
+			errs["offset"] = is.ErrInt
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return request, nil
+}
+
+func (a AdminLoginHistoryRequest) Validate() error {
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.Email, is.Email),
+		validation.Field(&a.IP, is.IP),
+		validation.Field(&a.Result, validation.In(
+			db.LoginResultSuccess,
+			db.LoginResultBadPassword,
+			db.LoginResultLocked,
+			db.LoginResultMFAFailed,
+			db.LoginResultEmailNotVerified,
+		)),
+		validation.Field(&a.Limit, validation.Required, validation.Min(1), validation.Max(maxAdminLoginHistoryLimit)),
+		validation.Field(&a.Offset, validation.Min(0)),
+	)
+}
+
+type AdminLoginHistoryHandler struct {
+	log *zap.Logger
+}
+
+func NewAdminLoginHistoryHandler(log *zap.Logger) *AdminLoginHistoryHandler {
+	return &AdminLoginHistoryHandler{log: log}
+}
+
+func (h AdminLoginHistoryHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request, err := NewAdminLoginHistoryRequest(r)
+	if err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	history, err := DB(r).GetLoginHistory(db.LoginHistoryFilter{
+		UserID: request.UserID,
+		Email:  request.Email,
+		IP:     request.IP,
+		Result: request.Result,
+		Since:  request.Since,
+		Until:  request.Until,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	})
+	if err != nil {
+		h.log.With(zap.Error(err)).Error("failed to get login history")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if err := WriteJSON(w, http.StatusOK, NewLoginHistoryListResponse(history)); err != nil {
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+
+	"github.com/anfimovoleh/ms-users/utils"
+)
+
+// Authenticator rejects requests without a valid access token, tokens issued
+// for other audiences, tokens which were revoked on logout, tokens of revoked
+// sessions and tokens issued before the user logged out everywhere.
+// It has to be preceded by the jwtauth.Verifier middleware.
+func Authenticator(log *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, claims, err := utils.User(r.Context(), DB(r))
+			if err != nil {
+				if err == utils.ErrInvalidSession {
+					httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidSession)
+					return
+				}
+
+				log.With(zap.Error(err)).Error("failed to get session user")
+				httperr.InternalServerError(w)
+				return
+			}
+
+			authentication := Authentication(r)
+			if claims.Issuer != authentication.Issuer || !hasAnyAudience(claims, authentication.Audience) {
+				httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidSession)
+				return
+			}
+
+			if user.TokenGeneration != claims.Generation {
+				httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidSession)
+				return
+			}
+
+			revoked, err := DB(r).IsTokenRevoked(claims.ID)
+			if err != nil {
+				log.With(
+					zap.String("jti", claims.ID),
+					zap.Error(err),
+				).Error("failed to check token revocation")
+				httperr.InternalServerError(w)
+				return
+			}
+
+			if revoked {
+				httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidSession)
+				return
+			}
+
+			if claims.Session != "" && !checkSession(w, r, log, claims.Session) {
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(CtxSession(claims)(r.Context())))
+		})
+	}
+}
+
+// checkSession rejects tokens of revoked sessions and keeps the last seen time
+// of the session up to date. Sessions of the logins made before they were
+// recorded are not known, their tokens are only checked by the token family.
+func checkSession(w http.ResponseWriter, r *http.Request, log *zap.Logger, familyID string) bool {
+	sessionID := HashToken(familyID)
+	log = log.With(zap.String("session_id", sessionID))
+
+	session, err := DB(r).GetSession(sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return true
+		}
+
+		log.With(zap.Error(err)).Error("failed to get session")
+		httperr.InternalServerError(w)
+		return false
+	}
+
+	if session.RevokedAt != nil {
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidSession)
+		return false
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		//skip err, the request is authenticated anyway
+		if err := DB(r).TouchSession(sessionID, now); err != nil {
+			log.With(zap.Error(err)).Error("failed to touch session")
+		}
+	}
+
+	return true
+}
+
+func hasAnyAudience(claims *utils.Claims, audience []string) bool {
+	for _, aud := range audience {
+		if claims.HasAudience(aud) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// userAgentMaxLength is the length the stored user agent is truncated to
+	userAgentMaxLength = 512
+	// deviceIPv4Prefix and deviceIPv6Prefix are the sizes of the networks a
+	// device is recognized in, so a reconnect doesn't make the device new
+	deviceIPv4Prefix = 24
+	deviceIPv6Prefix = 64
+)
+
+// ClientIP returns the IP of the client without the port. Behind a reverse
+// proxy the RealIP middleware has to set it from the forwarded headers.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// RealIP replaces the remote address of the requests coming from the trusted
+// proxies with the client IP from X-Forwarded-For. Proxies append to the
+// header, so its left part is supplied by the client and only the right-most
+// hop which is not a trusted proxy can be relied on.
+func RealIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := forwardedIP(r, trusted); ip != "" {
+				r.RemoteAddr = ip
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedIP returns an empty string if the request doesn't come from a
+// trusted proxy or carries no valid forwarded IP
+func forwardedIP(r *http.Request, trusted []*net.IPNet) string {
+	if !isTrustedProxy(net.ParseIP(ClientIP(r)), trusted) {
+		return ""
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			return ""
+		}
+
+		//every hop is a trusted proxy, so the first one is the client
+		if !isTrustedProxy(ip, trusted) || i == 0 {
+			return ip.String()
+		}
+	}
+
+	return ""
+}
+
+func isTrustedProxy(ip net.IP, trusted []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UserAgent returns the user agent of the client truncated to the length it
+// is stored with
+func UserAgent(r *http.Request) string {
+	return truncate(r.UserAgent(), userAgentMaxLength)
+}
+
+// truncate cuts s to at most max bytes without splitting a UTF-8 sequence
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+
+	return s[:max]
+}
+
+// DeviceFingerprint identifies the client by its user agent and the network
+// of its IP address
+func DeviceFingerprint(r *http.Request) string {
+	network := ClientIP(r)
+	if ip := net.ParseIP(network); ip != nil {
+		if v4 := ip.To4(); v4 != nil {
+			network = v4.Mask(net.CIDRMask(deviceIPv4Prefix, 32)).String()
+		} else {
+			network = ip.Mask(net.CIDRMask(deviceIPv6Prefix, 128)).String()
+		}
+	}
+
+	return HashToken(r.UserAgent() + "\n" + network)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		max   int
+		want  string
+	}{
+		{name: "short", value: "curl/7.68.0", max: 16, want: "curl/7.68.0"},
+		{name: "ascii", value: "Mozilla/5.0", max: 7, want: "Mozilla"},
+		{name: "rune boundary", value: "agent/Ω", max: 8, want: "agent/Ω"},
+		{name: "inside a rune", value: "agent/Ω", max: 7, want: "agent/"},
+		{name: "inside a 4 byte rune", value: "a😀", max: 3, want: "a"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := truncate(c.value, c.max); got != c.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", c.value, c.max, got, c.want)
+			}
+		})
+	}
+}
+
+func TestUserAgentIsValidUTF8(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/user/me", nil)
+	r.Header.Set("User-Agent", strings.Repeat("a", userAgentMaxLength-1)+"Ω")
+
+	userAgent := UserAgent(r)
+	if !utf8.ValidString(userAgent) {
+		t.Errorf("user agent %q is not valid UTF-8", userAgent[len(userAgent)-4:])
+	}
+	if len(userAgent) > userAgentMaxLength {
+		t.Errorf("user agent is %d bytes long, want at most %d", len(userAgent), userAgentMaxLength)
+	}
+}
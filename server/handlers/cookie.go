@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/anfimovoleh/ms-users/config"
+)
+
+// csrfTokenSize is the amount of random bytes in a CSRF token
+const csrfTokenSize = 32
+
+// TokenFromCookie returns the lookup of the access token in the session
+// cookie for the jwt verifier
+func TokenFromCookie(sessionCookie *config.SessionCookie) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		cookie, err := r.Cookie(sessionCookie.AccessTokenName)
+		if err != nil {
+			return ""
+		}
+
+		return cookie.Value
+	}
+}
+
+// RefreshTokenFromCookie returns an empty string if the session cookie mode
+// is disabled or the request has no refresh token cookie
+func RefreshTokenFromCookie(r *http.Request) string {
+	sessionCookie := SessionCookie(r)
+	if !sessionCookie.Enabled {
+		return ""
+	}
+
+	cookie, err := r.Cookie(sessionCookie.RefreshTokenName)
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
+
+// WriteTokens writes the login response. In the session cookie mode the
+// tokens are set as HttpOnly cookies along with a new CSRF token and are
+// left out of the body.
+func WriteTokens(w http.ResponseWriter, r *http.Request, status int, result LoginResponse) error {
+	sessionCookie := SessionCookie(r)
+	if !sessionCookie.Enabled || (result.Token == "" && result.RefreshToken == "") {
+		return WriteJSON(w, status, result)
+	}
+
+	raw := make([]byte, csrfTokenSize)
+	if _, err := rand.Read(raw); err != nil {
+		return errors.Wrap(err, "failed to generate csrf token")
+	}
+
+	authentication := Authentication(r)
+
+	if result.Token != "" {
+		http.SetCookie(w, newSessionCookie(sessionCookie, sessionCookie.AccessTokenName,
+			result.Token, authentication.AccessTokenTTL, true))
+	}
+
+	if result.RefreshToken != "" {
+		http.SetCookie(w, newSessionCookie(sessionCookie, sessionCookie.RefreshTokenName,
+			result.RefreshToken, authentication.RefreshTokenTTL, true))
+	}
+
+	//the web app has to read it to send it back in the header
+	http.SetCookie(w, newSessionCookie(sessionCookie, sessionCookie.CSRFTokenName,
+		base64.RawURLEncoding.EncodeToString(raw), authentication.RefreshTokenTTL, false))
+
+	result.Token = ""
+	result.RefreshToken = ""
+
+	return WriteJSON(w, status, result)
+}
+
+// ClearTokenCookies expires the session cookies, it does nothing if the
+// session cookie mode is disabled
+func ClearTokenCookies(w http.ResponseWriter, r *http.Request) {
+	sessionCookie := SessionCookie(r)
+	if !sessionCookie.Enabled {
+		return
+	}
+
+	for _, name := range []string{
+		sessionCookie.AccessTokenName,
+		sessionCookie.RefreshTokenName,
+		sessionCookie.CSRFTokenName,
+	} {
+		cookie := newSessionCookie(sessionCookie, name, "", 0, name != sessionCookie.CSRFTokenName)
+		cookie.MaxAge = -1
+		http.SetCookie(w, cookie)
+	}
+}
+
+func newSessionCookie(sessionCookie *config.SessionCookie, name, value string, ttl time.Duration, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     sessionCookie.Path,
+		Domain:   sessionCookie.Domain,
+		MaxAge:   int(ttl / time.Second),
+		Secure:   sessionCookie.Secure,
+		HttpOnly: httpOnly,
+		SameSite: sessionCookie.SameSiteMode(),
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/anfimovoleh/httperr"
+
+	"github.com/anfimovoleh/ms-users/config"
+)
+
+// CSRFTokenHeader carries the value of the CSRF token cookie
+const CSRFTokenHeader = "X-CSRF-Token"
+
+// CSRF protects the state-changing requests carrying the session cookies with
+// double-submit tokens, the CSRFTokenHeader has to match the CSRF token
+// cookie. Requests without the session cookies, like the ones of clients
+// sending the Authorization header, are passed through.
+func CSRF(sessionCookie *config.SessionCookie) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !hasCookie(r, sessionCookie.AccessTokenName) && !hasCookie(r, sessionCookie.RefreshTokenName) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cookie, err := r.Cookie(sessionCookie.CSRFTokenName)
+			if err != nil || cookie.Value == "" {
+				httperr.ErrResponse(w, http.StatusForbidden, ErrInvalidCSRFToken)
+				return
+			}
+
+			header := r.Header.Get(CSRFTokenHeader)
+			if subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) != 1 {
+				httperr.ErrResponse(w, http.StatusForbidden, ErrInvalidCSRFToken)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func hasCookie(r *http.Request, name string) bool {
+	_, err := r.Cookie(name)
+	return err == nil
+}
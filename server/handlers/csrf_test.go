@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anfimovoleh/ms-users/config"
+)
+
+func TestCSRF(t *testing.T) {
+	sessionCookie := &config.SessionCookie{
+		AccessTokenName:  "jwt",
+		RefreshTokenName: "refresh_token",
+		CSRFTokenName:    "csrf_token",
+	}
+
+	cases := []struct {
+		name    string
+		method  string
+		cookies map[string]string
+		header  string
+		want    int
+	}{
+		{
+			name:    "safe method",
+			method:  http.MethodGet,
+			cookies: map[string]string{"jwt": "token"},
+			want:    http.StatusNoContent,
+		},
+		{
+			name:   "no session cookies",
+			method: http.MethodPost,
+			want:   http.StatusNoContent,
+		},
+		{
+			name:    "no csrf cookie",
+			method:  http.MethodPost,
+			cookies: map[string]string{"jwt": "token"},
+			header:  "csrf",
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "no csrf header",
+			method:  http.MethodPost,
+			cookies: map[string]string{"jwt": "token", "csrf_token": "csrf"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "csrf header mismatch",
+			method:  http.MethodDelete,
+			cookies: map[string]string{"jwt": "token", "csrf_token": "csrf"},
+			header:  "other",
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "refresh token cookie only",
+			method:  http.MethodPost,
+			cookies: map[string]string{"refresh_token": "token", "csrf_token": "csrf"},
+			header:  "other",
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "csrf header match",
+			method:  http.MethodPost,
+			cookies: map[string]string{"jwt": "token", "csrf_token": "csrf"},
+			header:  "csrf",
+			want:    http.StatusNoContent,
+		},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := CSRF(sessionCookie)(next)
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(c.method, "/user/me", nil)
+			for name, value := range c.cookies {
+				r.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+			if c.header != "" {
+				r.Header.Set(CSRFTokenHeader, c.header)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != c.want {
+				t.Errorf("status = %d, want %d", w.Code, c.want)
+			}
+		})
+	}
+}
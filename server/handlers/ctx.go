@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/anfimovoleh/ms-users/config"
 	"github.com/anfimovoleh/ms-users/db"
 	"github.com/anfimovoleh/ms-users/email"
-	"github.com/go-chi/jwtauth"
+	"github.com/anfimovoleh/ms-users/signing"
+	"github.com/anfimovoleh/ms-users/utils"
 )
 
 type CtxKey int
@@ -18,6 +20,13 @@ const (
 	emailClientCtxKey
 	dbCtxKey
 	jwtCtxKey
+	tokensCtxKey
+	authenticationCtxKey
+	sessionCtxKey
+	mfaCtxKey
+	webAuthnCtxKey
+	passwordCtxKey
+	sessionCookieCtxKey
 )
 
 func CtxWebApp(webApp *url.URL) func(context.Context) context.Context {
@@ -59,12 +68,82 @@ func DB(r *http.Request) *db.DB {
 	return r.Context().Value(dbCtxKey).(*db.DB)
 }
 
-func CtxJWT(entry *jwtauth.JWTAuth) func(context.Context) context.Context {
+func CtxJWT(entry *signing.JWTAuth) func(context.Context) context.Context {
 	return func(ctx context.Context) context.Context {
 		return context.WithValue(ctx, jwtCtxKey, entry)
 	}
 }
 
-func JWT(r *http.Request) *jwtauth.JWTAuth {
-	return r.Context().Value(jwtCtxKey).(*jwtauth.JWTAuth)
+func JWT(r *http.Request) *signing.JWTAuth {
+	return r.Context().Value(jwtCtxKey).(*signing.JWTAuth)
+}
+
+func CtxTokens(tokens *config.Tokens) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, tokensCtxKey, tokens)
+	}
+}
+
+func Tokens(r *http.Request) *config.Tokens {
+	return r.Context().Value(tokensCtxKey).(*config.Tokens)
+}
+
+func CtxAuthentication(authentication *config.Authentication) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, authenticationCtxKey, authentication)
+	}
+}
+
+func Authentication(r *http.Request) *config.Authentication {
+	return r.Context().Value(authenticationCtxKey).(*config.Authentication)
+}
+
+func CtxSession(session *utils.Claims) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, sessionCtxKey, session)
+	}
+}
+
+func Session(r *http.Request) *utils.Claims {
+	return r.Context().Value(sessionCtxKey).(*utils.Claims)
+}
+
+func CtxMFA(mfa *config.MFA) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, mfaCtxKey, mfa)
+	}
+}
+
+func MFA(r *http.Request) *config.MFA {
+	return r.Context().Value(mfaCtxKey).(*config.MFA)
+}
+
+func CtxWebAuthn(webAuthn *config.WebAuthn) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, webAuthnCtxKey, webAuthn)
+	}
+}
+
+func WebAuthn(r *http.Request) *config.WebAuthn {
+	return r.Context().Value(webAuthnCtxKey).(*config.WebAuthn)
+}
+
+func CtxPassword(password *config.Password) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, passwordCtxKey, password)
+	}
+}
+
+func Password(r *http.Request) *config.Password {
+	return r.Context().Value(passwordCtxKey).(*config.Password)
+}
+
+func CtxSessionCookie(sessionCookie *config.SessionCookie) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, sessionCookieCtxKey, sessionCookie)
+	}
+}
+
+func SessionCookie(r *http.Request) *config.SessionCookie {
+	return r.Context().Value(sessionCookieCtxKey).(*config.SessionCookie)
 }
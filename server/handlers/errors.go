@@ -6,4 +6,28 @@ import (
 
 var (
 	ErrInvalidEmailOrPassword = errors.New("invalid email or password")
+	ErrEmailNotVerified       = errors.New("email address is not verified")
+	ErrInvalidRefreshToken    = errors.New("invalid refresh token")
+	ErrInvalidSession         = errors.New("invalid session")
+	ErrMFAAlreadyEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled         = errors.New("two-factor authentication is not enrolled")
+	ErrMFANotEnabled          = errors.New("two-factor authentication is not enabled")
+	ErrInvalidMFACode         = errors.New("invalid two-factor authentication code")
+	ErrInvalidMFAChallenge    = errors.New("two-factor authentication challenge is invalid or expired")
+	ErrMFAUnavailable         = errors.New("two-factor authentication is unavailable, try again later")
+	ErrInvalidPasskey         = errors.New("invalid passkey")
+	ErrInvalidPasskeySession  = errors.New("passkey session is invalid or expired")
+	ErrInvalidMagicLink       = errors.New("sign in link is invalid, expired or was already used")
+	ErrPasswordPolicy         = errors.New("password does not satisfy the password policy")
+	ErrTooManyLoginAttempts   = errors.New("too many failed login attempts, try again later")
+	ErrTooManyRequests        = errors.New("too many requests, try again later")
+	ErrInvalidPassword        = errors.New("invalid password")
+	ErrSameEmail              = errors.New("new email address is the current one")
+	ErrEmailTaken             = errors.New("email address is already taken")
+	ErrInvalidEmailChangeLink = errors.New("email change link is invalid, expired or was already used")
+	ErrInvalidNotMeLink       = errors.New("sign out link is invalid, expired or was already used")
+	ErrInvalidResetLink       = errors.New("reset link is invalid, expired or was already used")
+	ErrInvalidVerifyLink      = errors.New("verification link is invalid, expired or was already used")
+	ErrAdminRequired          = errors.New("admin permissions are required")
+	ErrInvalidCSRFToken       = errors.New("invalid csrf token")
 )
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+
+	"github.com/anfimovoleh/ms-users/signing"
+)
+
+type JWKSHandler struct {
+	log *zap.Logger
+}
+
+func NewJWKSHandler(log *zap.Logger) *JWKSHandler {
+	return &JWKSHandler{log: log}
+}
+
+func (h JWKSHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	keys, err := JWT(r).PublicKeys()
+	if err != nil {
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to get public keys")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	maxAge := int(signing.JWKSMaxAge / time.Second)
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+	if err := WriteJSON(w, http.StatusOK, keys); err != nil {
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
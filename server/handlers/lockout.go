@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+
+	"github.com/anfimovoleh/ms-users/config"
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+// AccountLockoutKey is the key the failed attempts of the account with the
+// email are counted by, the email is hashed to fit the key of any length
+func AccountLockoutKey(email string) string {
+	return "account:" + HashToken(strings.ToLower(email))
+}
+
+// AttemptAccount counts the attempt against the account of the user up front,
+// a successful attempt has to reset the counter. The response is written if
+// the attempt is rejected.
+func AttemptAccount(w http.ResponseWriter, r *http.Request, log *zap.Logger, lockout *config.Lockout, user *db.User) bool {
+	now := time.Now()
+
+	wait, locked, err := lockout.Accounts().Attempt(AccountLockoutKey(user.Email), now)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to check login attempts")
+		httperr.InternalServerError(w)
+		return false
+	}
+
+	if locked {
+		NotifyAccountLocked(r, log, user, now.Add(wait))
+	}
+
+	if wait > 0 {
+		w.Header().Set("Retry-After", seconds(wait))
+		httperr.ErrResponse(w, http.StatusTooManyRequests, ErrTooManyLoginAttempts)
+		return false
+	}
+
+	return true
+}
+
+// ResetAccount forgets the failed attempts of the account of the user
+func ResetAccount(log *zap.Logger, lockout *config.Lockout, user *db.User) {
+	if err := lockout.Accounts().Reset(AccountLockoutKey(user.Email)); err != nil {
+		log.With(zap.Error(err)).Error("failed to reset login attempts")
+	}
+}
+
+// NotifyAccountLocked emails the owner of the account, a failure is only
+// logged
+func NotifyAccountLocked(r *http.Request, log *zap.Logger, user *db.User, until time.Time) {
+	//skip err for Email client
+	if err := EmailClient(r).AccountLocked(user.Email, until); err != nil {
+		log.With(zap.Error(err)).Error("failed to send account locked email")
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+// loginHistoryEmailMaxLength is the length the attempted email is truncated
+// to, the login request doesn't limit it
+const loginHistoryEmailMaxLength = 255
+
+// LoginHistoryResponse is a recorded login attempt
+type LoginHistoryResponse struct {
+	ID        uint64         `json:"id"`
+	UserID    *uint64        `json:"user_id"`
+	Email     string         `json:"email"`
+	IP        string         `json:"ip"`
+	UserAgent string         `json:"user_agent"`
+	Result    db.LoginResult `json:"result"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type LoginHistoryListResponse struct {
+	LoginHistory []LoginHistoryResponse `json:"login_history"`
+}
+
+func NewLoginHistoryListResponse(history []db.LoginHistory) LoginHistoryListResponse {
+	result := LoginHistoryListResponse{LoginHistory: make([]LoginHistoryResponse, 0, len(history))}
+	for _, entry := range history {
+		result.LoginHistory = append(result.LoginHistory, LoginHistoryResponse{
+			ID:        entry.ID,
+			UserID:    entry.UserID,
+			Email:     entry.Email,
+			IP:        entry.IP,
+			UserAgent: entry.UserAgent,
+			Result:    entry.Result,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+
+	return result
+}
+
+// RecordLogin stores the login attempt, user is nil if the email is unknown.
+// A failure is only logged, it must not change the outcome of the login.
+func RecordLogin(r *http.Request, log *zap.Logger, user *db.User, email string, result db.LoginResult) {
+	entry := &db.LoginHistory{
+		Email:     truncate(email, loginHistoryEmailMaxLength),
+		IP:        ClientIP(r),
+		UserAgent: UserAgent(r),
+		Result:    result,
+		CreatedAt: time.Now(),
+	}
+
+	if user != nil {
+		entry.UserID = &user.ID
+	}
+
+	if err := DB(r).CreateLoginHistory(entry); err != nil {
+		log.With(
+			zap.String("email", email),
+			zap.String("result", string(result)),
+			zap.Error(err),
+		).Error("failed to record login attempt")
+	}
+}
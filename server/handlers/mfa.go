@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/anfimovoleh/ms-users/db"
+	"github.com/anfimovoleh/ms-users/mfa"
+)
+
+// CheckTOTP validates the code against the user secret, an accepted code
+// can't be used again
+func CheckTOTP(r *http.Request, totp *db.TOTP, code string) (bool, error) {
+	secret, err := MFA(r).Cipher().Decrypt(totp.Secret)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to decrypt TOTP secret")
+	}
+
+	step, ok := mfa.ValidateTOTP(string(secret), code, time.Now(), totp.LastUsedStep)
+	if !ok {
+		return false, nil
+	}
+
+	used, err := DB(r).UseTOTPStep(totp.UserID, step)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to store TOTP step")
+	}
+
+	return used, nil
+}
+
+// NewRecoveryCodes replaces the recovery codes of the user, the plain codes
+// are returned to be shown to the user once
+func NewRecoveryCodes(r *http.Request, userID uint64) ([]string, error) {
+	codes, err := mfa.GenerateRecoveryCodes(mfa.RecoveryCodesCount)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stored := make([]*db.RecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		hash, err := bcrypt.GenerateFromPassword([]byte(mfa.NormalizeRecoveryCode(code)), 8)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to hash recovery code")
+		}
+
+		stored = append(stored, &db.RecoveryCode{
+			UserID:    userID,
+			CodeHash:  string(hash),
+			CreatedAt: now,
+		})
+	}
+
+	if err := DB(r).ReplaceRecoveryCodes(userID, stored); err != nil {
+		return nil, errors.Wrap(err, "failed to store recovery codes")
+	}
+
+	return codes, nil
+}
+
+// CheckRecoveryCode consumes the matching unused recovery code, the amount
+// of codes left is returned along
+func CheckRecoveryCode(r *http.Request, userID uint64, code string) (bool, int, error) {
+	codes, err := DB(r).GetUnusedRecoveryCodes(userID)
+	if err != nil {
+		return false, 0, errors.Wrap(err, "failed to get recovery codes")
+	}
+
+	code = mfa.NormalizeRecoveryCode(code)
+	for _, stored := range codes {
+		if bcrypt.CompareHashAndPassword([]byte(stored.CodeHash), []byte(code)) != nil {
+			continue
+		}
+
+		used, err := DB(r).UseRecoveryCode(stored.ID, time.Now())
+		if err != nil {
+			return false, 0, errors.Wrap(err, "failed to use recovery code")
+		}
+
+		return used, len(codes) - 1, nil
+	}
+
+	return false, len(codes), nil
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/anfimovoleh/httperr"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/ms-users/db"
+	"github.com/anfimovoleh/ms-users/password"
+)
+
+// PasswordPolicyResponse has the same shape as the other errors plus the
+// codes of every violated rule, so they can be shown next to the field
+type PasswordPolicyResponse struct {
+	Code       int                  `json:"code"`
+	Error      string               `json:"error"`
+	Violations []password.Violation `json:"violations"`
+}
+
+// CheckPasswordPolicy writes the violations and returns false if the new
+// password is rejected by the policy
+func CheckPasswordPolicy(
+	w http.ResponseWriter, r *http.Request, log *zap.Logger, newPassword string, owner password.Owner,
+) bool {
+	violations, err := Password(r).Policy().Check(newPassword, owner)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to check password policy")
+		httperr.InternalServerError(w)
+		return false
+	}
+
+	if len(violations) == 0 {
+		return true
+	}
+
+	writePasswordViolations(w, log, violations)
+	return false
+}
+
+// CheckPasswordReuse rejects the current password of the user and the ones
+// kept in the password history
+func CheckPasswordReuse(w http.ResponseWriter, r *http.Request, log *zap.Logger, user *db.User, newPassword string) bool {
+	reused, err := passwordReused(r, user, newPassword)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to check password history")
+		httperr.InternalServerError(w)
+		return false
+	}
+
+	if !reused {
+		return true
+	}
+
+	writePasswordViolations(w, log, []password.Violation{password.ViolationReused})
+	return false
+}
+
+// dummyPassword is verified for unknown emails, so the response time doesn't
+// reveal whether the account exists
+var dummyPassword struct {
+	once sync.Once
+	hash string
+	err  error
+}
+
+// VerifyDummyPassword takes about the same time as verifying a real password
+func VerifyDummyPassword(r *http.Request, password string) error {
+	dummyPassword.once.Do(func() {
+		dummyPassword.hash, dummyPassword.err = Password(r).Hasher().Hash("dummy password")
+	})
+
+	if dummyPassword.err != nil {
+		return dummyPassword.err
+	}
+
+	_, _, err := Password(r).Hasher().Verify(password, dummyPassword.hash)
+	return err
+}
+
+// passwordReused checks the new password against the current one and the
+// ones it replaced before, which are kept in the password history
+func passwordReused(r *http.Request, user *db.User, newPassword string) (bool, error) {
+	hashes := []string{user.Password}
+	if Password(r).HistorySize > 0 {
+		history, err := DB(r).GetPasswordHistory(user.ID)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to get password history")
+		}
+
+		for _, previous := range history {
+			hashes = append(hashes, previous.Password)
+		}
+	}
+
+	return password.Reused(Password(r).Hasher(), newPassword, hashes)
+}
+
+func writePasswordViolations(w http.ResponseWriter, log *zap.Logger, violations []password.Violation) {
+	result := PasswordPolicyResponse{
+		Code:       http.StatusBadRequest,
+		Error:      ErrPasswordPolicy.Error(),
+		Violations: violations,
+	}
+
+	if err := WriteJSON(w, http.StatusBadRequest, result); err != nil {
+		log.With(zap.Error(err)).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anfimovoleh/httperr"
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/ms-users/config"
+	"github.com/anfimovoleh/ms-users/ratelimit"
+)
+
+// maxRateLimitBodySize bounds the body read to find the email address
+const maxRateLimitBodySize = 1 << 20
+
+// RateLimit limits the requests to the route per client IP and, if the route
+// has an email limit, per email address of the request body, which is hashed
+// to fit the key of any length. The most restrictive limit is reported in the
+// RateLimit-* headers.
+func RateLimit(log *zap.Logger, cfg *config.RateLimit) func(route string) func(http.Handler) http.Handler {
+	return func(route string) func(http.Handler) http.Handler {
+		limits := cfg.Route(route)
+
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				now := time.Now()
+
+				var results []*ratelimit.Result
+				if limits.IP != nil {
+					result, err := cfg.Limiter().Allow("ip:"+route+":"+ClientIP(r), *limits.IP, now)
+					if err != nil {
+						log.With(zap.Error(err)).Error("failed to check rate limit")
+						httperr.InternalServerError(w)
+						return
+					}
+					results = append(results, result)
+				}
+
+				if limits.Email != nil {
+					email, err := requestEmail(r)
+					if err != nil {
+						httperr.BadRequest(w, err)
+						return
+					}
+
+					if email != "" {
+						result, err := cfg.Limiter().Allow("email:"+route+":"+HashToken(email), *limits.Email, now)
+						if err != nil {
+							log.With(zap.Error(err)).Error("failed to check rate limit")
+							httperr.InternalServerError(w)
+							return
+						}
+						results = append(results, result)
+					}
+				}
+
+				if len(results) == 0 {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				result := mostRestrictive(results)
+				w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+				w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+				w.Header().Set("RateLimit-Reset", seconds(result.Reset))
+
+				if !result.Allowed {
+					w.Header().Set("Retry-After", seconds(result.Reset))
+					httperr.ErrResponse(w, http.StatusTooManyRequests, ErrTooManyRequests)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+}
+
+// requestEmail reads the email field of the JSON body and restores the body
+// for the handler, a body without the field is left to the handler to reject
+func requestEmail(r *http.Request) (string, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRateLimitBodySize))
+	if err != nil {
+		return "", err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var request struct {
+		Email string `json:"email"`
+	}
+	_ = json.Unmarshal(body, &request)
+
+	return strings.ToLower(strings.TrimSpace(request.Email)), nil
+}
+
+// mostRestrictive prefers a rejection, then the least remaining requests
+func mostRestrictive(results []*ratelimit.Result) *ratelimit.Result {
+	result := results[0]
+	for _, next := range results[1:] {
+		switch {
+		case result.Allowed && !next.Allowed:
+			result = next
+		case result.Allowed == next.Allowed && next.Remaining < result.Remaining:
+			result = next
+		case !result.Allowed && !next.Allowed && next.Reset > result.Reset:
+			result = next
+		}
+	}
+
+	return result
+}
+
+func seconds(duration time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(duration.Seconds())))
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+var serializer = jsoniter.Config{
+	EscapeHTML:             true,
+	SortMapKeys:            true,
+	ValidateJsonRawMessage: true,
+	TagKey:                 "json",
+}.Froze()
+
+// WriteJSON serializes the value and writes it with the status code.
+// Nothing is written if serialization fails.
+func WriteJSON(w http.ResponseWriter, status int, value interface{}) error {
+	response, err := serializer.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(response)
+	return nil
+}
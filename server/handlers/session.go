@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/anfimovoleh/httperr"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/ms-users/db"
+	"github.com/anfimovoleh/ms-users/utils"
+)
+
+const (
+	// refreshTokenSize is the amount of random bytes in a refresh token
+	refreshTokenSize = 32
+	// sessionTouchInterval limits how often the last seen time of a session
+	// is updated by the requests authenticated with its access tokens
+	sessionTouchInterval = time.Minute
+)
+
+// HashToken returns the representation under which opaque tokens are stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewAccessToken signs a short-lived access token for the user. The token is
+// bound to the refresh token family it was issued for and to the current token
+// generation of the user, so both can be used to revoke it.
+func NewAccessToken(r *http.Request, user *db.User, familyID string) (string, error) {
+	now := time.Now()
+	claims := utils.Claims{
+		UserID:        user.ID,
+		Issuer:        Authentication(r).Issuer,
+		Audience:      Authentication(r).Audience,
+		IssuedAt:      now,
+		NotBefore:     now,
+		ExpiresAt:     now.Add(Authentication(r).AccessTokenTTL),
+		ID:            uuid.NewString(),
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified(),
+		Session:       familyID,
+		Generation:    user.TokenGeneration,
+	}
+
+	_, token, err := JWT(r).Encode(claims.Map())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign access token")
+	}
+
+	return token, nil
+}
+
+// NewRefreshToken generates a refresh token for the family, the raw token is
+// handed out to the client while only its hash is persisted.
+func NewRefreshToken(r *http.Request, userID uint64, familyID string) (string, *db.RefreshToken, error) {
+	raw := make([]byte, refreshTokenSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, errors.Wrap(err, "failed to generate refresh token")
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	now := time.Now()
+
+	return token, &db.RefreshToken{
+		ID:        HashToken(token),
+		FamilyID:  familyID,
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(Authentication(r).RefreshTokenTTL),
+	}, nil
+}
+
+// NewSession records the client the refresh token family was issued to
+func NewSession(r *http.Request, userID uint64, familyID string) *db.Session {
+	now := time.Now()
+	return &db.Session{
+		ID:         HashToken(familyID),
+		UserID:     userID,
+		UserAgent:  UserAgent(r),
+		IP:         ClientIP(r),
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+}
+
+// IssueTokens starts a new session and refresh token family for the user and
+// returns the access and refresh token pair.
+func IssueTokens(r *http.Request, log *zap.Logger, user *db.User) (*LoginResponse, error) {
+	familyID := uuid.NewString()
+
+	session := NewSession(r, user.ID, familyID)
+	if err := DB(r).CreateSession(session); err != nil {
+		return nil, errors.Wrap(err, "failed to store session")
+	}
+
+	RecordLogin(r, log, user, user.Email, db.LoginResultSuccess)
+	NotifyNewDevice(r, log, user, session)
+
+	accessToken, err := NewAccessToken(r, user, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, stored, err := NewRefreshToken(r, user.ID, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := DB(r).CreateRefreshToken(stored); err != nil {
+		return nil, errors.Wrap(err, "failed to store refresh token")
+	}
+
+	return &LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// NotifyNewDevice emails the user when the session was started from a device
+// the user has never signed in from. The first device of the user is only
+// remembered. Failures are logged, they must not break the login.
+func NotifyNewDevice(r *http.Request, log *zap.Logger, user *db.User, session *db.Session) {
+	log = log.With(zap.Uint64("user_id", user.ID))
+
+	devices, err := DB(r).CountKnownDevices(user.ID)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to count known devices")
+		return
+	}
+
+	isNew, err := DB(r).RememberDevice(&db.KnownDevice{
+		UserID:      user.ID,
+		Fingerprint: DeviceFingerprint(r),
+		CreatedAt:   session.CreatedAt,
+		LastSeenAt:  session.CreatedAt,
+	})
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to remember device")
+		return
+	}
+
+	if !isNew || devices == 0 {
+		return
+	}
+
+	token := NewToken(r, user.ID, db.TokenPurposeRevokeSession)
+	token.Payload = session.ID
+	if err := DB(r).CreateToken(token); err != nil {
+		log.With(zap.Error(err)).Error("failed to create token")
+		return
+	}
+
+	//link to web app "this wasn't me" page
+	link := fmt.Sprintf("%s/not-me?token=%s", WebApp(r).String(), token.Token)
+
+	//skip err for Email client
+	if err := EmailClient(r).NewSignIn(user.Email, session.UserAgent, session.IP, session.CreatedAt, link); err != nil {
+		log.With(zap.Error(err)).Error("failed to send new sign in email")
+	}
+}
+
+// StartSession completes the first authentication factor. The token pair is
+// returned right away unless the user enabled MFA, in that case a short-lived
+// challenge token is returned instead.
+func StartSession(w http.ResponseWriter, r *http.Request, log *zap.Logger, user *db.User) {
+	log = log.With(zap.Uint64("user_id", user.ID))
+
+	required, ok := mfaRequired(w, r, log, user.ID)
+	if !ok {
+		return
+	}
+
+	var result *LoginResponse
+	var err error
+	if required {
+		challenge := NewToken(r, user.ID, db.TokenPurposeMFAChallenge)
+		if err := DB(r).CreateToken(challenge); err != nil {
+			log.With(zap.Error(err)).Error("failed to create mfa challenge")
+			httperr.InternalServerError(w)
+			return
+		}
+
+		result = &LoginResponse{
+			MFARequired: true,
+			MFAToken:    challenge.Token,
+		}
+	} else {
+		result, err = IssueTokens(r, log, user)
+		if err != nil {
+			log.With(zap.Error(err)).Error("failed to issue tokens")
+			httperr.InternalServerError(w)
+			return
+		}
+	}
+
+	if err := WriteTokens(w, r, http.StatusAccepted, *result); err != nil {
+		log.With(zap.Error(err)).Error("failed to write tokens")
+		httperr.InternalServerError(w)
+		return
+	}
+}
+
+// mfaRequired returns true if the user has enabled MFA. The login of such a
+// user is refused while the MFA encryption key is not configured, so the
+// second factor is never skipped. The response is written if ok is false.
+func mfaRequired(w http.ResponseWriter, r *http.Request, log *zap.Logger, userID uint64) (required bool, ok bool) {
+	totp, err := DB(r).GetTOTP(userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, true
+		}
+
+		log.With(zap.Error(err)).Error("failed to get user TOTP")
+		httperr.InternalServerError(w)
+		return false, false
+	}
+
+	if !totp.Enabled() {
+		return false, true
+	}
+
+	if !MFA(r).Enabled() {
+		log.Error("user has MFA enabled, but the MFA encryption key is not configured")
+		httperr.ErrResponse(w, http.StatusServiceUnavailable, ErrMFAUnavailable)
+		return false, false
+	}
+
+	return true, true
+}
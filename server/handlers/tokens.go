@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+// NewToken builds an emailed token for the purpose with the configured lifetime
+func NewToken(r *http.Request, userID uint64, purpose db.TokenPurpose) *db.Token {
+	now := time.Now()
+	return &db.Token{
+		Token:      uuid.NewString(),
+		UserID:     userID,
+		Purpose:    purpose,
+		LastSentAt: now,
+		ExpiresAt:  now.Add(Tokens(r).TTL(purpose)),
+	}
+}
@@ -10,12 +10,10 @@ import (
 
 	"github.com/anfimovoleh/httperr"
 
-	validation "github.com/go-ozzo/ozzo-validation/v4"
-
-	"github.com/dgrijalva/jwt-go"
+	"github.com/anfimovoleh/ms-users/config"
+	"github.com/anfimovoleh/ms-users/db"
 
-	jsoniter "github.com/json-iterator/go"
-	"golang.org/x/crypto/bcrypt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 type LoginRequest struct {
@@ -30,18 +28,23 @@ func (c LoginRequest) Validate() error {
 	)
 }
 
+// LoginResponse either holds the token pair or, if the user enabled MFA,
+// the challenge token to be exchanged at /user/login/mfa
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
 }
 
-const tokenExpirationDuration = time.Hour
-
 type LoginHandler struct {
-	log *zap.Logger
+	log     *zap.Logger
+	login   *config.Login
+	lockout *config.Lockout
 }
 
-func NewLoginHandler(log *zap.Logger) *LoginHandler {
-	return &LoginHandler{log: log}
+func NewLoginHandler(log *zap.Logger, login *config.Login, lockout *config.Lockout) *LoginHandler {
+	return &LoginHandler{log: log, login: login, lockout: lockout}
 }
 
 func (h LoginHandler) Handle(w http.ResponseWriter, r *http.Request) {
@@ -56,13 +59,13 @@ func (h LoginHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := DB(r).GetUser(loginRequest.Email)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			httperr.BadRequest(w, err)
-			return
-		}
+	//unknown emails are counted and locked as well, so the responses
+	//don't reveal whether the account exists
+	accountKey := AccountLockoutKey(loginRequest.Email)
+	ipKey := "ip:" + ClientIP(r)
 
+	user, err := DB(r).GetUser(loginRequest.Email)
+	if err != nil && err != sql.ErrNoRows {
 		h.log.With(
 			zap.String("email", loginRequest.Email),
 			zap.Error(err),
@@ -71,46 +74,130 @@ func (h LoginHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	//the attempts of unknown emails are recorded without the user
+	if err == sql.ErrNoRows {
+		user = nil
+	}
+
+	//the attempt is counted as failed before the password is verified, so
+	//parallel requests can't all pass the check
+	wait, err := h.attempt(r, user, accountKey, ipKey)
+	if err != nil {
+		h.log.With(zap.Error(err)).Error("failed to check login attempts")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if wait > 0 {
+		RecordLogin(r, h.log, user, loginRequest.Email, db.LoginResultLocked)
+		w.Header().Set("Retry-After", seconds(wait))
+		httperr.ErrResponse(w, http.StatusTooManyRequests, ErrTooManyLoginAttempts)
+		return
+	}
+
 	if user == nil {
+		if err := VerifyDummyPassword(r, loginRequest.Password); err != nil {
+			h.log.With(zap.Error(err)).Error("failed to verify dummy password")
+		}
+
+		RecordLogin(r, h.log, nil, loginRequest.Email, db.LoginResultBadPassword)
 		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidEmailOrPassword)
 		return
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginRequest.Password))
+	ok, rehash, err := Password(r).Hasher().Verify(loginRequest.Password, user.Password)
 	if err != nil {
+		h.log.With(
+			zap.Uint64("user_id", user.ID),
+			zap.Error(err),
+		).Error("failed to verify password")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !ok {
+		RecordLogin(r, h.log, user, loginRequest.Email, db.LoginResultBadPassword)
 		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidEmailOrPassword)
 		return
 	}
-	_, token, err := JWT(r).Encode(
-		jwt.MapClaims{
-			"id":  user.ID,
-			"exp": time.Now().Add(tokenExpirationDuration).Unix(),
-		},
-	)
-	if err != nil {
-		httperr.BadRequest(w, err)
+
+	//with MFA the account is only reset once the second factor is verified
+	required, ok := mfaRequired(w, r, h.log.With(zap.Uint64("user_id", user.ID)), user.ID)
+	if !ok {
 		return
 	}
 
-	result := LoginResponse{
-		Token: token,
+	h.succeed(user, accountKey, ipKey, !required)
+
+	//upgrade hashes of the legacy algorithm or outdated parameters
+	if rehash {
+		h.rehashPassword(r, user, loginRequest.Password)
+	}
+
+	if h.login.RequireVerifiedEmail && !user.EmailVerified() {
+		RecordLogin(r, h.log, user, loginRequest.Email, db.LoginResultEmailNotVerified)
+		httperr.ErrResponse(w, http.StatusForbidden, ErrEmailNotVerified)
+		return
 	}
 
-	serializer := jsoniter.Config{
-		EscapeHTML:             true,
-		SortMapKeys:            true,
-		ValidateJsonRawMessage: true,
-		TagKey:                 "json",
-	}.Froze()
-	response, err := serializer.Marshal(result)
+	StartSession(w, r, h.log, user)
+}
+
+// attempt counts the attempt of the client IP and the account and returns
+// the wait if either of them is rejected. The owner of an existing account is
+// notified once it gets locked.
+func (h LoginHandler) attempt(r *http.Request, user *db.User, accountKey, ipKey string) (time.Duration, error) {
+	now := time.Now()
+
+	wait, _, err := h.lockout.IPs().Attempt(ipKey, now)
+	if err != nil || wait > 0 {
+		return wait, err
+	}
+
+	wait, locked, err := h.lockout.Accounts().Attempt(accountKey, now)
 	if err != nil {
-		h.log.With(
-			zap.Error(err),
-		).Error("failed to serialize response")
-		httperr.InternalServerError(w)
+		return 0, err
+	}
+
+	if !locked || user == nil {
+		return wait, nil
+	}
+
+	NotifyAccountLocked(r, h.log.With(zap.Uint64("user_id", user.ID)), user, now.Add(wait))
+	return wait, nil
+}
+
+// succeed takes back the failure counted by attempt from the client IP, the
+// failures of the account are forgotten if resetAccount is set
+func (h LoginHandler) succeed(user *db.User, accountKey, ipKey string, resetAccount bool) {
+	log := h.log.With(zap.Uint64("user_id", user.ID))
+
+	if resetAccount {
+		if err := h.lockout.Accounts().Reset(accountKey); err != nil {
+			log.With(zap.Error(err)).Error("failed to reset login attempts")
+		}
+	}
+
+	if err := h.lockout.IPs().Forgive(ipKey, time.Now()); err != nil {
+		log.With(zap.Error(err)).Error("failed to forgive login attempt")
+	}
+}
+
+// rehashPassword replaces the stored hash, a failure doesn't prevent the login
+// as the old hash is still valid
+func (h LoginHandler) rehashPassword(r *http.Request, user *db.User, password string) {
+	log := h.log.With(zap.Uint64("user_id", user.ID))
+
+	hash, err := Password(r).Hasher().Hash(password)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to rehash password")
+		return
+	}
+
+	if err := DB(r).UpdateUserPasswordHash(user.ID, hash); err != nil {
+		log.With(zap.Error(err)).Error("failed to store rehashed password")
 		return
 	}
 
-	w.WriteHeader(http.StatusAccepted)
-	_, _ = w.Write(response)
+	user.Password = hash
 }
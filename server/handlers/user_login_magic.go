@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+type MagicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+func (m MagicLinkRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Email, validation.Required, is.Email),
+	)
+}
+
+type MagicLinkHandler struct {
+	log *zap.Logger
+}
+
+func NewMagicLinkHandler(log *zap.Logger) *MagicLinkHandler {
+	return &MagicLinkHandler{log: log}
+}
+
+func (h MagicLinkHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &MagicLinkRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	user, err := DB(r).GetUser(request.Email)
+	if err != nil {
+		//return the same response for unknown emails by security reasons
+		if err == sql.ErrNoRows {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		h.log.With(
+			zap.String("email", request.Email),
+			zap.Error(err),
+		).Error("failed to get user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", user.ID))
+
+	token := NewToken(r, user.ID, db.TokenPurposeMagicLink)
+	if err := DB(r).CreateToken(token); err != nil {
+		log.With(zap.Error(err)).Error("failed to create token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	//link to web app page exchanging the token at /user/login/magic/verify
+	link := fmt.Sprintf("%s/magic-login?token=%s", WebApp(r).String(), token.Token)
+
+	//skip err for Email client
+	if err := EmailClient(r).MagicLink(user.Email, link); err != nil {
+		log.With(zap.Error(err)).Error("failed to send magic link email")
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type MagicLinkLoginRequest struct {
+	Token string `json:"token"`
+}
+
+func (m MagicLinkLoginRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Token, validation.Required),
+	)
+}
+
+type MagicLinkLoginHandler struct {
+	log *zap.Logger
+}
+
+func NewMagicLinkLoginHandler(log *zap.Logger) *MagicLinkLoginHandler {
+	return &MagicLinkLoginHandler{log: log}
+}
+
+func (h MagicLinkLoginHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &MagicLinkLoginRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	token, err := DB(r).GetUserByToken(request.Token, db.TokenPurposeMagicLink)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidMagicLink)
+			return
+		}
+
+		h.log.With(zap.Error(err)).Error("failed to get user token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", token.UserID))
+
+	ok, err := DB(r).UseToken(token.Token)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to delete magic link token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !ok {
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidMagicLink)
+		return
+	}
+
+	user, err := DB(r).GetUserByID(token.UserID)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to get user by id")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	//following the link proves the ownership of the email address
+	if !user.EmailVerified() {
+		now := time.Now()
+		if err := DB(r).SetUserEmailVerified(user.ID, now); err != nil {
+			log.With(zap.Error(err)).Error("failed to mark user email as verified")
+			httperr.InternalServerError(w)
+			return
+		}
+
+		user.EmailVerifiedAt = &now
+	}
+
+	StartSession(w, r, h.log, user)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/anfimovoleh/ms-users/config"
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+// LoginMFARequest completes the login either with the TOTP code or with
+// one of the recovery codes
+type LoginMFARequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+func (l LoginMFARequest) Validate() error {
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.MFAToken, validation.Required),
+		validation.Field(&l.Code, validation.Required.When(l.RecoveryCode == "")),
+		validation.Field(&l.RecoveryCode, validation.Empty.When(l.Code != "")),
+	)
+}
+
+type LoginMFAHandler struct {
+	log     *zap.Logger
+	lockout *config.Lockout
+}
+
+func NewLoginMFAHandler(log *zap.Logger, lockout *config.Lockout) *LoginMFAHandler {
+	return &LoginMFAHandler{log: log, lockout: lockout}
+}
+
+func (h LoginMFAHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &LoginMFARequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	challenge, err := DB(r).GetUserByToken(request.MFAToken, db.TokenPurposeMFAChallenge)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidMFAChallenge)
+			return
+		}
+
+		h.log.With(zap.Error(err)).Error("failed to get mfa challenge")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", challenge.UserID))
+
+	user, err := DB(r).GetUserByID(challenge.UserID)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to get user by id")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	//codes are guessed against the same counter as passwords, the challenge
+	//is kept if the attempt is rejected
+	if !AttemptAccount(w, r, log, h.lockout, user) {
+		return
+	}
+
+	//the challenge is single-use, a wrong code requires to log in with the password again
+	ok, err := DB(r).UseToken(challenge.Token)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to delete mfa challenge")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !ok {
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidMFAChallenge)
+		return
+	}
+
+	if request.RecoveryCode != "" {
+		ok, err = h.checkRecoveryCode(r, user, request.RecoveryCode)
+	} else {
+		ok, err = h.checkTOTP(r, user, request.Code)
+	}
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to check second factor")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !ok {
+		RecordLogin(r, log, user, user.Email, db.LoginResultMFAFailed)
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidMFACode)
+		return
+	}
+
+	ResetAccount(log, h.lockout, user)
+
+	result, err := IssueTokens(r, log, user)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to issue tokens")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if err := WriteTokens(w, r, http.StatusAccepted, *result); err != nil {
+		log.With(zap.Error(err)).Error("failed to write tokens")
+		httperr.InternalServerError(w)
+		return
+	}
+}
+
+func (h LoginMFAHandler) checkTOTP(r *http.Request, user *db.User, code string) (bool, error) {
+	totp, err := DB(r).GetTOTP(user.ID)
+	if err != nil {
+		//MFA was disabled after the challenge was issued
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return CheckTOTP(r, totp, code)
+}
+
+func (h LoginMFAHandler) checkRecoveryCode(r *http.Request, user *db.User, code string) (bool, error) {
+	ok, remaining, err := CheckRecoveryCode(r, user.ID, code)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	//skip err for Email client, the code is already consumed
+	if err := EmailClient(r).RecoveryCodeUsed(user.Email, remaining); err != nil {
+		h.log.With(zap.Error(err)).Error("failed to send recovery code used notification")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+type NotMeRequest struct {
+	Token string `json:"token"`
+}
+
+func (n NotMeRequest) Validate() error {
+	return validation.ValidateStruct(&n,
+		validation.Field(&n.Token, validation.Required),
+	)
+}
+
+// NotMeHandler handles the "this wasn't me" link of the new sign in email,
+// it revokes the session the email was sent for and starts a password reset.
+type NotMeHandler struct {
+	log *zap.Logger
+}
+
+func NewNotMeHandler(log *zap.Logger) *NotMeHandler {
+	return &NotMeHandler{log: log}
+}
+
+func (h NotMeHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &NotMeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	token, err := DB(r).GetUserByToken(request.Token, db.TokenPurposeRevokeSession)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httperr.BadRequest(w, ErrInvalidNotMeLink)
+			return
+		}
+
+		h.log.With(zap.Error(err)).Error("failed to get user token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(
+		zap.Uint64("user_id", token.UserID),
+		zap.String("session_id", token.Payload),
+	)
+
+	ok, err := DB(r).UseToken(token.Token)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to delete revoke session token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !ok {
+		httperr.BadRequest(w, ErrInvalidNotMeLink)
+		return
+	}
+
+	//the session could be already signed out, the password is reset anyway
+	if _, err := DB(r).RevokeSession(token.UserID, token.Payload); err != nil {
+		log.With(zap.Error(err)).Error("failed to revoke session")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	user, err := DB(r).GetUserByID(token.UserID)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to get user by id")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !StartPasswordReset(w, r, log, user) {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
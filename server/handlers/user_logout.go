@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+type LogoutHandler struct {
+	log *zap.Logger
+}
+
+func NewLogoutHandler(log *zap.Logger) *LogoutHandler {
+	return &LogoutHandler{log: log}
+}
+
+func (h LogoutHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	session := Session(r)
+
+	revoked := &db.RevokedToken{
+		JTI:       session.ID,
+		UserID:    session.UserID,
+		ExpiresAt: session.ExpiresAt,
+	}
+
+	if err := DB(r).RevokeToken(revoked); err != nil {
+		h.log.With(
+			zap.String("jti", session.ID),
+			zap.Error(err),
+		).Error("failed to revoke access token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	//refresh tokens of the same login must not be able to mint new access tokens
+	if session.Session != "" {
+		if err := DB(r).RevokeRefreshTokenFamily(session.Session); err != nil {
+			h.log.With(
+				zap.String("family_id", session.Session),
+				zap.Error(err),
+			).Error("failed to revoke refresh token family")
+			httperr.InternalServerError(w)
+			return
+		}
+
+		if _, err := DB(r).RevokeSession(session.UserID, HashToken(session.Session)); err != nil {
+			h.log.With(
+				zap.String("family_id", session.Session),
+				zap.Error(err),
+			).Error("failed to revoke session")
+			httperr.InternalServerError(w)
+			return
+		}
+	}
+
+	ClearTokenCookies(w, r)
+	w.WriteHeader(http.StatusNoContent)
+}
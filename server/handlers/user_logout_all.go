@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+)
+
+type LogoutAllHandler struct {
+	log *zap.Logger
+}
+
+func NewLogoutAllHandler(log *zap.Logger) *LogoutAllHandler {
+	return &LogoutAllHandler{log: log}
+}
+
+func (h LogoutAllHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	session := Session(r)
+
+	if err := DB(r).RevokeUserSessions(session.UserID); err != nil {
+		h.log.With(
+			zap.Uint64("user_id", session.UserID),
+			zap.Error(err),
+		).Error("failed to revoke user sessions")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	ClearTokenCookies(w, r)
+	w.WriteHeader(http.StatusNoContent)
+}
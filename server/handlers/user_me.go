@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+
+	"github.com/anfimovoleh/ms-users/db"
+	"github.com/anfimovoleh/ms-users/utils"
+)
+
+// UserResponse is the public representation of the user, it never exposes
+// the password hash
+type UserResponse struct {
+	ID              uint64     `json:"id"`
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	Phone           string     `json:"phone"`
+	DateOfBirth     string     `json:"date_of_birth"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
+
+func NewUserResponse(user *db.User) UserResponse {
+	return UserResponse{
+		ID:              user.ID,
+		Email:           user.Email,
+		Name:            user.Name,
+		Phone:           user.Phone,
+		DateOfBirth:     user.DateOfBirth,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+	}
+}
+
+type GetMeHandler struct {
+	log *zap.Logger
+}
+
+func NewGetMeHandler(log *zap.Logger) *GetMeHandler {
+	return &GetMeHandler{log: log}
+}
+
+func (h GetMeHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	user, _, err := utils.User(r.Context(), DB(r))
+	if err != nil {
+		h.log.With(
+			zap.Uint64("user_id", Session(r).UserID),
+			zap.Error(err),
+		).Error("failed to get session user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if err := WriteJSON(w, http.StatusOK, NewUserResponse(user)); err != nil {
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
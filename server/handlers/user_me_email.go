@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+
+	"github.com/anfimovoleh/ms-users/config"
+	"github.com/anfimovoleh/ms-users/db"
+	"github.com/anfimovoleh/ms-users/utils"
+)
+
+// ChangeEmailRequest requires the current password, so a stolen session
+// can't be used to take the account over
+type ChangeEmailRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (c ChangeEmailRequest) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Email, validation.Required, is.Email, validation.Length(1, 254)),
+		validation.Field(&c.Password, validation.Required),
+	)
+}
+
+type ChangeEmailHandler struct {
+	log     *zap.Logger
+	lockout *config.Lockout
+}
+
+func NewChangeEmailHandler(log *zap.Logger, lockout *config.Lockout) *ChangeEmailHandler {
+	return &ChangeEmailHandler{log: log, lockout: lockout}
+}
+
+func (h ChangeEmailHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &ChangeEmailRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	user, _, err := utils.User(r.Context(), DB(r))
+	if err != nil {
+		h.log.With(
+			zap.Uint64("user_id", Session(r).UserID),
+			zap.Error(err),
+		).Error("failed to get session user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", user.ID))
+
+	//the current password is guessed against the same counter as the login
+	if !AttemptAccount(w, r, log, h.lockout, user) {
+		return
+	}
+
+	ok, _, err := Password(r).Hasher().Verify(request.Password, user.Password)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to verify password")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !ok {
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidPassword)
+		return
+	}
+
+	ResetAccount(log, h.lockout, user)
+
+	if strings.EqualFold(request.Email, user.Email) {
+		httperr.BadRequest(w, ErrSameEmail)
+		return
+	}
+
+	_, err = DB(r).GetUser(request.Email)
+	if err == nil {
+		httperr.ErrResponse(w, http.StatusConflict, ErrEmailTaken)
+		return
+	}
+
+	if err != sql.ErrNoRows {
+		log.With(zap.Error(err)).Error("failed to get user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	token := NewToken(r, user.ID, db.TokenPurposeChangeEmail)
+	token.Payload = request.Email
+	if err := DB(r).CreateToken(token); err != nil {
+		log.With(zap.Error(err)).Error("failed to create token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	//link to web app email change confirmation page
+	link := fmt.Sprintf("%s/confirm-email?token=%s", WebApp(r).String(), token.Token)
+
+	//skip err for Email client
+	if err := EmailClient(r).ChangeEmail(request.Email, link); err != nil {
+		log.With(zap.Error(err)).Error("failed to send change email confirmation")
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type EmailTokenRequest struct {
+	Token string `json:"token"`
+}
+
+func (e EmailTokenRequest) Validate() error {
+	return validation.ValidateStruct(&e,
+		validation.Field(&e.Token, validation.Required),
+	)
+}
+
+// ConfirmEmailHandler swaps the email once the new address is confirmed and
+// sends a revert link to the previous one
+type ConfirmEmailHandler struct {
+	log *zap.Logger
+}
+
+func NewConfirmEmailHandler(log *zap.Logger) *ConfirmEmailHandler {
+	return &ConfirmEmailHandler{log: log}
+}
+
+func (h ConfirmEmailHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &EmailTokenRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	token, err := DB(r).GetUserByToken(request.Token, db.TokenPurposeChangeEmail)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httperr.BadRequest(w, ErrInvalidEmailChangeLink)
+			return
+		}
+
+		h.log.With(zap.Error(err)).Error("failed to get user token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", token.UserID))
+
+	user, err := DB(r).GetUserByID(token.UserID)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to get user by id")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	previousEmail := user.Email
+	if !ChangeEmail(w, r, log, token) {
+		return
+	}
+
+	//only the confirmed request is applied, the other pending ones are dropped
+	if err := DB(r).DeleteUserTokens(user.ID, db.TokenPurposeChangeEmail); err != nil {
+		log.With(zap.Error(err)).Error("failed to delete change email tokens")
+	}
+
+	revertToken := NewToken(r, user.ID, db.TokenPurposeRevertEmail)
+	revertToken.Payload = previousEmail
+	if err := DB(r).CreateToken(revertToken); err != nil {
+		log.With(zap.Error(err)).Error("failed to create token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	//link to web app email change revert page
+	link := fmt.Sprintf("%s/revert-email?token=%s", WebApp(r).String(), revertToken.Token)
+
+	//skip err for Email client, the email is already changed
+	if err := EmailClient(r).EmailChanged(previousEmail, token.Payload, link); err != nil {
+		log.With(zap.Error(err)).Error("failed to send email changed notification")
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RevertEmailHandler restores the previous email after an unwanted change and
+// signs the user out everywhere, as the account may be compromised
+type RevertEmailHandler struct {
+	log *zap.Logger
+}
+
+func NewRevertEmailHandler(log *zap.Logger) *RevertEmailHandler {
+	return &RevertEmailHandler{log: log}
+}
+
+func (h RevertEmailHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &EmailTokenRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	token, err := DB(r).GetUserByToken(request.Token, db.TokenPurposeRevertEmail)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httperr.BadRequest(w, ErrInvalidEmailChangeLink)
+			return
+		}
+
+		h.log.With(zap.Error(err)).Error("failed to get user token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", token.UserID))
+
+	if !ChangeEmail(w, r, log, token) {
+		return
+	}
+
+	if err := DB(r).DeleteUserTokens(token.UserID, db.TokenPurposeChangeEmail); err != nil {
+		log.With(zap.Error(err)).Error("failed to delete change email tokens")
+	}
+
+	if err := DB(r).RevokeUserSessions(token.UserID); err != nil {
+		log.With(zap.Error(err)).Error("failed to revoke user sessions")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ChangeEmail sets the email carried by the token and consumes the token,
+// the response is written if the email can't be changed
+func ChangeEmail(w http.ResponseWriter, r *http.Request, log *zap.Logger, token *db.Token) bool {
+	changed, err := DB(r).ChangeUserEmail(token.UserID, token.Payload, time.Now(), token.Token)
+	if err != nil {
+		if err == db.ErrEmailTaken {
+			httperr.ErrResponse(w, http.StatusConflict, ErrEmailTaken)
+			return false
+		}
+
+		log.With(zap.Error(err)).Error("failed to change user email")
+		httperr.InternalServerError(w)
+		return false
+	}
+
+	if !changed {
+		httperr.BadRequest(w, ErrInvalidEmailChangeLink)
+		return false
+	}
+
+	return true
+}
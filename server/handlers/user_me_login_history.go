@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+const (
+	defaultLoginHistoryLimit = 20
+	maxLoginHistoryLimit     = 100
+)
+
+type LoginHistoryRequest struct {
+	Limit int `json:"limit"`
+}
+
+// NewLoginHistoryRequest reads the request from the query string
+func NewLoginHistoryRequest(r *http.Request) (*LoginHistoryRequest, error) {
+	request := &LoginHistoryRequest{Limit: defaultLoginHistoryLimit}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		var err error
+		if request.Limit, err = strconv.Atoi(limit); err != nil {
+			return nil, validation.Errors{"limit": is.ErrInt}
+		}
+	}
+
+	return request, nil
+}
+
+func (l LoginHistoryRequest) Validate() error {
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.Limit, validation.Required, validation.Min(1), validation.Max(maxLoginHistoryLimit)),
+	)
+}
+
+type GetLoginHistoryHandler struct {
+	log *zap.Logger
+}
+
+func NewGetLoginHistoryHandler(log *zap.Logger) *GetLoginHistoryHandler {
+	return &GetLoginHistoryHandler{log: log}
+}
+
+func (h GetLoginHistoryHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request, err := NewLoginHistoryRequest(r)
+	if err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	userID := Session(r).UserID
+	history, err := DB(r).GetLoginHistory(db.LoginHistoryFilter{
+		UserID: &userID,
+		Limit:  request.Limit,
+	})
+	if err != nil {
+		h.log.With(
+			zap.Uint64("user_id", userID),
+			zap.Error(err),
+		).Error("failed to get login history")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if err := WriteJSON(w, http.StatusOK, NewLoginHistoryListResponse(history)); err != nil {
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/anfimovoleh/ms-users/config"
+	"github.com/anfimovoleh/ms-users/password"
+	"github.com/anfimovoleh/ms-users/utils"
+)
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	Password        string `json:"password"`
+}
+
+func (c ChangePasswordRequest) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.CurrentPassword, validation.Required),
+		validation.Field(&c.Password, validation.Required),
+	)
+}
+
+// ChangePasswordHandler signs the user out of every other session, the
+// current one gets a new access token
+type ChangePasswordHandler struct {
+	log     *zap.Logger
+	lockout *config.Lockout
+}
+
+func NewChangePasswordHandler(log *zap.Logger, lockout *config.Lockout) *ChangePasswordHandler {
+	return &ChangePasswordHandler{log: log, lockout: lockout}
+}
+
+func (h ChangePasswordHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &ChangePasswordRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	user, _, err := utils.User(r.Context(), DB(r))
+	if err != nil {
+		h.log.With(
+			zap.Uint64("user_id", Session(r).UserID),
+			zap.Error(err),
+		).Error("failed to get session user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", user.ID))
+
+	//the current password is guessed against the same counter as the login
+	if !AttemptAccount(w, r, log, h.lockout, user) {
+		return
+	}
+
+	ok, _, err := Password(r).Hasher().Verify(request.CurrentPassword, user.Password)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to verify password")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !ok {
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidPassword)
+		return
+	}
+
+	ResetAccount(log, h.lockout, user)
+
+	owner := password.Owner{Email: user.Email, Name: user.Name}
+	if !CheckPasswordPolicy(w, r, log, request.Password, owner) {
+		return
+	}
+
+	if !CheckPasswordReuse(w, r, log, user, request.Password) {
+		return
+	}
+
+	hashedPassword, err := Password(r).Hasher().Hash(request.Password)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to hash password")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	user.Password = hashedPassword
+	if err := DB(r).SetUserNewPassword(user, Password(r).HistorySize); err != nil {
+		log.With(zap.Error(err)).Error("failed to update user password")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	session := Session(r)
+	if err := DB(r).RevokeOtherUserSessions(user.ID, session.Session, HashToken(session.Session)); err != nil {
+		log.With(zap.Error(err)).Error("failed to revoke other sessions")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	//the token generation was bumped, the current access token is revoked as well
+	user, err = DB(r).GetUserByID(user.ID)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to get user by id")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	accessToken, err := NewAccessToken(r, user, session.Session)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to issue access token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	//skip err for Email client, the password is already changed
+	if err := EmailClient(r).NewPassword(user.Email); err != nil {
+		log.With(zap.Error(err)).Error("failed to send notification about new password")
+	}
+
+	if err := WriteTokens(w, r, http.StatusOK, LoginResponse{Token: accessToken}); err != nil {
+		log.With(zap.Error(err)).Error("failed to write tokens")
+		httperr.InternalServerError(w)
+		return
+	}
+}
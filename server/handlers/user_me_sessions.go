@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+// SessionResponse describes a login of the user, ID is the hash of the
+// session so it can't be used to obtain tokens
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+type SessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+func NewSessionResponse(session db.Session, currentID string) SessionResponse {
+	return SessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		Current:    session.ID == currentID,
+	}
+}
+
+type GetSessionsHandler struct {
+	log *zap.Logger
+}
+
+func NewGetSessionsHandler(log *zap.Logger) *GetSessionsHandler {
+	return &GetSessionsHandler{log: log}
+}
+
+func (h GetSessionsHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	session := Session(r)
+
+	//sessions which were not refreshed within the refresh token TTL are expired
+	since := time.Now().Add(-Authentication(r).RefreshTokenTTL)
+	sessions, err := DB(r).GetUserSessions(session.UserID, since)
+	if err != nil {
+		h.log.With(
+			zap.Uint64("user_id", session.UserID),
+			zap.Error(err),
+		).Error("failed to get user sessions")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	result := SessionsResponse{Sessions: make([]SessionResponse, 0, len(sessions))}
+	for _, s := range sessions {
+		result.Sessions = append(result.Sessions, NewSessionResponse(s, HashToken(session.Session)))
+	}
+
+	if err := WriteJSON(w, http.StatusOK, result); err != nil {
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
+
+type RevokeSessionHandler struct {
+	log *zap.Logger
+}
+
+func NewRevokeSessionHandler(log *zap.Logger) *RevokeSessionHandler {
+	return &RevokeSessionHandler{log: log}
+}
+
+// Handle revokes the session, its access tokens are rejected by the
+// Authenticator and its refresh tokens by the token refresh from now on
+func (h RevokeSessionHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	session := Session(r)
+	sessionID := chi.URLParam(r, "id")
+
+	revoked, err := DB(r).RevokeSession(session.UserID, sessionID)
+	if err != nil {
+		h.log.With(
+			zap.Uint64("user_id", session.UserID),
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		).Error("failed to revoke session")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !revoked {
+		httperr.NotFound(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
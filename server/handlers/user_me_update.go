@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/anfimovoleh/ms-users/utils"
+)
+
+// UpdateMeRequest only changes the fields present in the request body
+type UpdateMeRequest struct {
+	Name        *string `json:"name"`
+	Phone       *string `json:"phone"`
+	DateOfBirth *string `json:"date_of_birth"`
+}
+
+func (u UpdateMeRequest) Validate() error {
+	return validation.ValidateStruct(&u,
+		validation.Field(&u.Name, validation.NilOrNotEmpty, validation.Length(1, 255)),
+		validation.Field(&u.Phone, validation.NilOrNotEmpty, validation.Length(1, 50)),
+		validation.Field(&u.DateOfBirth, validation.NilOrNotEmpty, validation.Date("2006-01-02")),
+	)
+}
+
+type UpdateMeHandler struct {
+	log *zap.Logger
+}
+
+func NewUpdateMeHandler(log *zap.Logger) *UpdateMeHandler {
+	return &UpdateMeHandler{log: log}
+}
+
+func (h UpdateMeHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &UpdateMeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	user, _, err := utils.User(r.Context(), DB(r))
+	if err != nil {
+		h.log.With(
+			zap.Uint64("user_id", Session(r).UserID),
+			zap.Error(err),
+		).Error("failed to get session user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if request.Name != nil {
+		user.Name = *request.Name
+	}
+	if request.Phone != nil {
+		user.Phone = *request.Phone
+	}
+	if request.DateOfBirth != nil {
+		user.DateOfBirth = *request.DateOfBirth
+	}
+
+	if err := DB(r).UpdateUserProfile(user); err != nil {
+		h.log.With(
+			zap.Uint64("user_id", user.ID),
+			zap.Error(err),
+		).Error("failed to update user profile")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if err := WriteJSON(w, http.StatusOK, NewUserResponse(user)); err != nil {
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+)
+
+type RegenerateRecoveryCodesHandler struct {
+	log *zap.Logger
+}
+
+func NewRegenerateRecoveryCodesHandler(log *zap.Logger) *RegenerateRecoveryCodesHandler {
+	return &RegenerateRecoveryCodesHandler{log: log}
+}
+
+func (h RegenerateRecoveryCodesHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &TOTPCodeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", Session(r).UserID))
+
+	totp, err := DB(r).GetTOTP(Session(r).UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httperr.ErrResponse(w, http.StatusConflict, ErrMFANotEnabled)
+			return
+		}
+
+		log.With(zap.Error(err)).Error("failed to get user TOTP")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !totp.Enabled() {
+		httperr.ErrResponse(w, http.StatusConflict, ErrMFANotEnabled)
+		return
+	}
+
+	ok, err := CheckTOTP(r, totp, request.Code)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to check TOTP code")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !ok {
+		httperr.BadRequest(w, ErrInvalidMFACode)
+		return
+	}
+
+	codes, err := NewRecoveryCodes(r, totp.UserID)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to create recovery codes")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if err := WriteJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes}); err != nil {
+		log.With(zap.Error(err)).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type TOTPCodeRequest struct {
+	Code string `json:"code"`
+}
+
+func (t TOTPCodeRequest) Validate() error {
+	return validation.ValidateStruct(&t,
+		validation.Field(&t.Code, validation.Required, is.Digit, validation.Length(6, 6)),
+	)
+}
+
+// RecoveryCodesResponse holds the plain recovery codes, they are shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type ConfirmTOTPHandler struct {
+	log *zap.Logger
+}
+
+func NewConfirmTOTPHandler(log *zap.Logger) *ConfirmTOTPHandler {
+	return &ConfirmTOTPHandler{log: log}
+}
+
+func (h ConfirmTOTPHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &TOTPCodeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", Session(r).UserID))
+
+	totp, err := DB(r).GetTOTP(Session(r).UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httperr.ErrResponse(w, http.StatusConflict, ErrMFANotEnrolled)
+			return
+		}
+
+		log.With(zap.Error(err)).Error("failed to get user TOTP")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if totp.Enabled() {
+		httperr.ErrResponse(w, http.StatusConflict, ErrMFAAlreadyEnabled)
+		return
+	}
+
+	ok, err := CheckTOTP(r, totp, request.Code)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to check TOTP code")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !ok {
+		httperr.BadRequest(w, ErrInvalidMFACode)
+		return
+	}
+
+	codes, err := NewRecoveryCodes(r, totp.UserID)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to create recovery codes")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if err := DB(r).ConfirmTOTP(totp.UserID, time.Now()); err != nil {
+		log.With(zap.Error(err)).Error("failed to confirm TOTP")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if err := WriteJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes}); err != nil {
+		log.With(zap.Error(err)).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
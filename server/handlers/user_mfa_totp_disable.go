@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+)
+
+type DisableTOTPHandler struct {
+	log *zap.Logger
+}
+
+func NewDisableTOTPHandler(log *zap.Logger) *DisableTOTPHandler {
+	return &DisableTOTPHandler{log: log}
+}
+
+func (h DisableTOTPHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &TOTPCodeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", Session(r).UserID))
+
+	totp, err := DB(r).GetTOTP(Session(r).UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httperr.ErrResponse(w, http.StatusConflict, ErrMFANotEnabled)
+			return
+		}
+
+		log.With(zap.Error(err)).Error("failed to get user TOTP")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !totp.Enabled() {
+		httperr.ErrResponse(w, http.StatusConflict, ErrMFANotEnabled)
+		return
+	}
+
+	//a stolen access token alone must not be enough to turn the second factor off
+	ok, err := CheckTOTP(r, totp, request.Code)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to check TOTP code")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !ok {
+		httperr.BadRequest(w, ErrInvalidMFACode)
+		return
+	}
+
+	if err := DB(r).DeleteRecoveryCodes(totp.UserID); err != nil {
+		log.With(zap.Error(err)).Error("failed to delete recovery codes")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if err := DB(r).DeleteTOTP(totp.UserID); err != nil {
+		log.With(zap.Error(err)).Error("failed to delete TOTP")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+
+	"github.com/anfimovoleh/ms-users/db"
+	"github.com/anfimovoleh/ms-users/mfa"
+	"github.com/anfimovoleh/ms-users/utils"
+)
+
+type EnrollTOTPResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type EnrollTOTPHandler struct {
+	log *zap.Logger
+}
+
+func NewEnrollTOTPHandler(log *zap.Logger) *EnrollTOTPHandler {
+	return &EnrollTOTPHandler{log: log}
+}
+
+func (h EnrollTOTPHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	user, _, err := utils.User(r.Context(), DB(r))
+	if err != nil {
+		h.log.With(zap.Error(err)).Error("failed to get session user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", user.ID))
+
+	existing, err := DB(r).GetTOTP(user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		log.With(zap.Error(err)).Error("failed to get user TOTP")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if err == nil && existing.Enabled() {
+		httperr.ErrResponse(w, http.StatusConflict, ErrMFAAlreadyEnabled)
+		return
+	}
+
+	key, err := mfa.GenerateTOTP(MFA(r).Issuer, user.Email)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to generate TOTP secret")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	secret, err := MFA(r).Cipher().Encrypt([]byte(key.Secret()))
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to encrypt TOTP secret")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	totp := &db.TOTP{
+		UserID:    user.ID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+
+	if err := DB(r).SetTOTP(totp); err != nil {
+		log.With(zap.Error(err)).Error("failed to store TOTP secret")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	result := EnrollTOTPResponse{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+	}
+
+	if err := WriteJSON(w, http.StatusCreated, result); err != nil {
+		log.With(zap.Error(err)).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
-	"errors"
 	"net/http"
 
 	"go.uber.org/zap"
@@ -12,8 +11,7 @@ import (
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 
 	"github.com/anfimovoleh/ms-users/db"
-
-	"golang.org/x/crypto/bcrypt"
+	"github.com/anfimovoleh/ms-users/password"
 )
 
 type NewPasswordRequest struct {
@@ -48,10 +46,10 @@ func (h NewPasswordHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := DB(r).GetUserByToken(request.Token)
+	token, err := DB(r).GetUserByToken(request.Token, db.TokenPurposeResetPassword)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			httperr.BadRequest(w, errors.New("Verification email was already used"))
+			httperr.BadRequest(w, ErrInvalidResetLink)
 			return
 		}
 
@@ -62,47 +60,74 @@ func (h NewPasswordHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if token == nil {
-		httperr.BadRequest(w, errors.New("no such user with provided token address"))
+	user, err := DB(r).GetUserByID(token.UserID)
+	if err != nil {
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to get user by id")
+		httperr.InternalServerError(w)
 		return
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), 8)
+	owner := password.Owner{Email: user.Email, Name: user.Name}
+	if !CheckPasswordPolicy(w, r, h.log, request.Password, owner) {
+		return
+	}
+
+	if !CheckPasswordReuse(w, r, h.log, user, request.Password) {
+		return
+	}
+
+	hashedPassword, err := Password(r).Hasher().Hash(request.Password)
 	if err != nil {
-		httperr.BadRequest(w, err)
+		h.log.With(zap.Error(err)).Error("failed to hash password")
+		httperr.InternalServerError(w)
 		return
 	}
 
-	if err := DB(r).SetUserNewPassword(&db.User{ID: token.UserID, Password: string(hashedPassword)}); err != nil {
+	//consume the token before the change, so concurrent requests can't
+	//both set a password with it
+	ok, err := DB(r).UseToken(token.Token)
+	if err != nil {
 		h.log.With(
 			zap.Error(err),
-		).Error("failed to update user password")
+		).Error("failed to delete reset password token")
 		httperr.InternalServerError(w)
 		return
 	}
 
-	if err := DB(r).DeleteToken(request.Token); err != nil {
+	if !ok {
+		httperr.BadRequest(w, ErrInvalidResetLink)
+		return
+	}
+
+	user.Password = hashedPassword
+	if err := DB(r).SetUserNewPassword(user, Password(r).HistorySize); err != nil {
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to update user password")
 		httperr.InternalServerError(w)
 		return
 	}
 
-	user, err := DB(r).GetUserByID(token.UserID)
-	if err != nil {
+	//whoever knew the old password may still be signed in, e.g. after
+	//"this wasn't me", so every session of the user is revoked
+	if err := DB(r).RevokeUserSessions(user.ID); err != nil {
 		h.log.With(
 			zap.Error(err),
-		).Error("failed to get user by id")
+		).Error("failed to revoke user sessions")
 		httperr.InternalServerError(w)
 		return
 	}
 
-	//notify user about password changing
+	//notify user about password changing, the password is already changed
+	//skip err for Email client
 	if err := EmailClient(r).NewPassword(user.Email); err != nil {
 		h.log.With(
 			zap.String("email_client", "notification"),
-			zap.Any("token", token),
+			zap.Uint64("user_id", user.ID),
+			zap.Error(err),
 		).Error("failed to send notification about new password")
-		httperr.InternalServerError(w)
-		return
 	}
 
 	w.WriteHeader(http.StatusOK)
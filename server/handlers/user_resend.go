@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+type ResendRequest struct {
+	Email string `json:"email"`
+}
+
+func (r ResendRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Email, validation.Required, is.Email),
+	)
+}
+
+// ResendResponse tells the client how long to wait before the next resend
+type ResendResponse struct {
+	RetryAfter int `json:"retry_after"`
+}
+
+// ResendHandler sends the email of a verification or password reset token
+// again, the still valid token is reused
+type ResendHandler struct {
+	log     *zap.Logger
+	purpose db.TokenPurpose
+}
+
+func NewResendVerificationHandler(log *zap.Logger) *ResendHandler {
+	return &ResendHandler{log: log, purpose: db.TokenPurposeVerifyEmail}
+}
+
+func NewResendResetPasswordHandler(log *zap.Logger) *ResendHandler {
+	return &ResendHandler{log: log, purpose: db.TokenPurposeResetPassword}
+}
+
+func (h ResendHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &ResendRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	cooldown := Tokens(r).ResendCooldown
+
+	user, err := DB(r).GetUser(request.Email)
+	if err != nil {
+		//return the same response for unknown emails by security reasons
+		if err == sql.ErrNoRows {
+			h.respond(w, http.StatusAccepted, cooldown)
+			return
+		}
+
+		h.log.With(
+			zap.String("email", request.Email),
+			zap.Error(err),
+		).Error("failed to get user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(
+		zap.Uint64("user_id", user.ID),
+		zap.String("purpose", string(h.purpose)),
+	)
+
+	if h.purpose == db.TokenPurposeVerifyEmail && user.EmailVerified() {
+		h.respond(w, http.StatusAccepted, cooldown)
+		return
+	}
+
+	now := time.Now()
+	token, err := DB(r).GetLastUserToken(user.ID, h.purpose)
+	switch {
+	case err == sql.ErrNoRows:
+		token = NewToken(r, user.ID, h.purpose)
+		if err := DB(r).CreateToken(token); err != nil {
+			log.With(zap.Error(err)).Error("failed to create token")
+			httperr.InternalServerError(w)
+			return
+		}
+	case err != nil:
+		log.With(zap.Error(err)).Error("failed to get user token")
+		httperr.InternalServerError(w)
+		return
+	default:
+		if wait := token.LastSentAt.Add(cooldown).Sub(now); wait > 0 {
+			w.Header().Set("Retry-After", seconds(wait))
+			h.respond(w, http.StatusTooManyRequests, wait)
+			return
+		}
+
+		touched, err := DB(r).TouchToken(token.Token, now, now.Add(-cooldown))
+		if err != nil {
+			log.With(zap.Error(err)).Error("failed to update token")
+			httperr.InternalServerError(w)
+			return
+		}
+
+		//a concurrent request has just sent it
+		if !touched {
+			w.Header().Set("Retry-After", seconds(cooldown))
+			h.respond(w, http.StatusTooManyRequests, cooldown)
+			return
+		}
+	}
+
+	//skip err for Email client
+	if err := h.send(r, user, token); err != nil {
+		log.With(zap.Error(err)).Error("failed to resend email")
+	}
+
+	h.respond(w, http.StatusAccepted, cooldown)
+}
+
+func (h ResendHandler) send(r *http.Request, user *db.User, token *db.Token) error {
+	switch h.purpose {
+	case db.TokenPurposeVerifyEmail:
+		link := fmt.Sprintf("%s/verify-email?token=%s", WebApp(r).String(), token.Token)
+		return EmailClient(r).Signup(user.Email, link)
+	default:
+		link := fmt.Sprintf("%s/recovery-password?token=%s", WebApp(r).String(), token.Token)
+		return EmailClient(r).Forgot(user.Email, link)
+	}
+}
+
+func (h ResendHandler) respond(w http.ResponseWriter, status int, retryAfter time.Duration) {
+	result := ResendResponse{RetryAfter: int(math.Ceil(retryAfter.Seconds()))}
+	if err := WriteJSON(w, status, result); err != nil {
+		h.log.With(zap.Error(err)).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
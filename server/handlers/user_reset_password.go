@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	"go.uber.org/zap"
 
@@ -16,7 +15,6 @@ import (
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
-	"github.com/google/uuid"
 )
 
 type ResetPasswordRequest struct {
@@ -69,27 +67,30 @@ func (h ResetPasswordHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token := uuid.NewString()
-
-	emailToken := &db.Token{
-		UserID:     user.ID,
-		Token:      token,
-		LastSentAt: time.Now(),
+	if !StartPasswordReset(w, r, h.log, user) {
+		return
 	}
 
+	w.WriteHeader(http.StatusOK)
+}
+
+// StartPasswordReset emails the user a link to the new password form, the
+// response is written if the reset token can't be created
+func StartPasswordReset(w http.ResponseWriter, r *http.Request, log *zap.Logger, user *db.User) bool {
+	emailToken := NewToken(r, user.ID, db.TokenPurposeResetPassword)
 	if err := DB(r).CreateToken(emailToken); err != nil {
-		h.log.With(zap.Error(err)).Error("failed to create token")
+		log.With(zap.Error(err)).Error("failed to create token")
 		httperr.InternalServerError(w)
-		return
+		return false
 	}
 
 	//link to web app new password form
-	link := fmt.Sprintf("%s/recovery-password?token=%s", WebApp(r).String(), token)
+	link := fmt.Sprintf("%s/recovery-password?token=%s", WebApp(r).String(), emailToken.Token)
 
 	//skip err for Email client
 	if err := EmailClient(r).Forgot(user.Email, link); err != nil {
-		h.log.With(zap.Error(err)).Error("failed to send forgot password email")
+		log.With(zap.Error(err)).Error("failed to send forgot password email")
 	}
 
-	w.WriteHeader(http.StatusOK)
+	return true
 }
@@ -4,8 +4,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"time"
 
 	"go.uber.org/zap"
 
@@ -15,9 +15,7 @@ import (
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 
 	"github.com/anfimovoleh/ms-users/db"
-
-	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/anfimovoleh/ms-users/password"
 )
 
 type SignupRequest struct {
@@ -57,9 +55,15 @@ func (h SignupHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(signupRequest.Password), 8)
+	owner := password.Owner{Email: signupRequest.Email, Name: signupRequest.Name}
+	if !CheckPasswordPolicy(w, r, h.log, signupRequest.Password, owner) {
+		return
+	}
+
+	hashedPassword, err := Password(r).Hasher().Hash(signupRequest.Password)
 	if err != nil {
-		httperr.BadRequest(w, err)
+		h.log.With(zap.Error(err)).Error("failed to hash password")
+		httperr.InternalServerError(w)
 		return
 	}
 
@@ -86,7 +90,7 @@ func (h SignupHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	dbUser := &db.User{
 		Name:        signupRequest.Name,
 		Email:       signupRequest.Email,
-		Password:    string(hashedPassword),
+		Password:    hashedPassword,
 		Phone:       signupRequest.Phone,
 		DateOfBirth: signupRequest.DateOfBirth,
 	}
@@ -115,18 +119,20 @@ func (h SignupHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token := uuid.NewString()
-	confirmToken := &db.Token{
-		UserID:     user.ID,
-		Token:      token,
-		LastSentAt: time.Now(),
-	}
-
+	confirmToken := NewToken(r, user.ID, db.TokenPurposeVerifyEmail)
 	if err := DB(r).CreateToken(confirmToken); err != nil {
 		h.log.With(zap.Error(err)).Error("failed to create token")
 		httperr.InternalServerError(w)
 		return
 	}
 
+	//link to web app email verification page
+	link := fmt.Sprintf("%s/verify-email?token=%s", WebApp(r).String(), confirmToken.Token)
+
+	//skip err for Email client, user is already created
+	if err := EmailClient(r).Signup(user.Email, link); err != nil {
+		h.log.With(zap.Error(err)).Error("failed to send sign up verification email")
+	}
+
 	w.WriteHeader(http.StatusCreated)
 }
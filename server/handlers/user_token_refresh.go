@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (t RefreshTokenRequest) Validate() error {
+	return validation.ValidateStruct(&t,
+		validation.Field(&t.RefreshToken, validation.Required),
+	)
+}
+
+type RefreshTokenHandler struct {
+	log *zap.Logger
+}
+
+func NewRefreshTokenHandler(log *zap.Logger) *RefreshTokenHandler {
+	return &RefreshTokenHandler{log: log}
+}
+
+func (h RefreshTokenHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	//browser clients in the session cookie mode send the token in the cookie
+	request := &RefreshTokenRequest{RefreshToken: RefreshTokenFromCookie(r)}
+	if request.RefreshToken == "" {
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			httperr.BadRequest(w, err)
+			return
+		}
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	stored, err := DB(r).GetRefreshToken(HashToken(request.RefreshToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidRefreshToken)
+			return
+		}
+
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to get refresh token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidRefreshToken)
+		return
+	}
+
+	//an already rotated token was presented, so one of the holders is not the legitimate client
+	if stored.RotatedAt != nil {
+		h.revokeFamily(w, r, stored.FamilyID, stored.UserID)
+		return
+	}
+
+	sessionID := HashToken(stored.FamilyID)
+	session, err := DB(r).GetSession(sessionID)
+	switch {
+	case err == sql.ErrNoRows:
+		//the family was started before sessions were recorded
+		err = DB(r).CreateSession(NewSession(r, stored.UserID, stored.FamilyID))
+	case err == nil && session.RevokedAt != nil:
+		if err := DB(r).RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+			h.log.With(
+				zap.String("family_id", stored.FamilyID),
+				zap.Error(err),
+			).Error("failed to revoke refresh token family")
+			httperr.InternalServerError(w)
+			return
+		}
+
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidRefreshToken)
+		return
+	case err == nil:
+		err = DB(r).TouchSession(sessionID, time.Now())
+	}
+
+	if err != nil {
+		h.log.With(
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		).Error("failed to update session")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	user, err := DB(r).GetUserByID(stored.UserID)
+	if err != nil {
+		h.log.With(
+			zap.Uint64("user_id", stored.UserID),
+			zap.Error(err),
+		).Error("failed to get user by id")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	refreshToken, next, err := NewRefreshToken(r, user.ID, stored.FamilyID)
+	if err != nil {
+		h.log.With(zap.Error(err)).Error("failed to create refresh token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	rotated, err := DB(r).RotateRefreshToken(stored.ID, next)
+	if err != nil {
+		h.log.With(
+			zap.Uint64("user_id", user.ID),
+			zap.Error(err),
+		).Error("failed to rotate refresh token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	//the token was rotated by a concurrent request in between
+	if !rotated {
+		h.revokeFamily(w, r, stored.FamilyID, stored.UserID)
+		return
+	}
+
+	accessToken, err := NewAccessToken(r, user, stored.FamilyID)
+	if err != nil {
+		h.log.With(zap.Error(err)).Error("failed to create access token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	result := LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}
+
+	if err := WriteTokens(w, r, http.StatusOK, result); err != nil {
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to write tokens")
+		httperr.InternalServerError(w)
+		return
+	}
+}
+
+func (h RefreshTokenHandler) revokeFamily(w http.ResponseWriter, r *http.Request, familyID string, userID uint64) {
+	log := h.log.With(
+		zap.String("family_id", familyID),
+		zap.Uint64("user_id", userID),
+	)
+	log.Warn("refresh token reuse detected, revoking token family")
+
+	if err := DB(r).RevokeRefreshTokenFamily(familyID); err != nil {
+		log.With(zap.Error(err)).Error("failed to revoke refresh token family")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidRefreshToken)
+}
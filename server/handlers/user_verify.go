@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+func (v VerifyEmailRequest) Validate() error {
+	return validation.ValidateStruct(&v,
+		validation.Field(&v.Token, validation.Required),
+	)
+}
+
+type VerifyEmailHandler struct {
+	log *zap.Logger
+}
+
+func NewVerifyEmailHandler(log *zap.Logger) *VerifyEmailHandler {
+	return &VerifyEmailHandler{log: log}
+}
+
+func (h VerifyEmailHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &VerifyEmailRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	token, err := DB(r).GetUserByToken(request.Token, db.TokenPurposeVerifyEmail)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httperr.BadRequest(w, ErrInvalidVerifyLink)
+			return
+		}
+
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to get user token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	ok, err := DB(r).UseToken(token.Token)
+	if err != nil {
+		h.log.With(
+			zap.Error(err),
+		).Error("failed to delete verification token")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if !ok {
+		httperr.BadRequest(w, ErrInvalidVerifyLink)
+		return
+	}
+
+	if err := DB(r).SetUserEmailVerified(token.UserID, time.Now()); err != nil {
+		h.log.With(
+			zap.Uint64("user_id", token.UserID),
+			zap.Error(err),
+		).Error("failed to mark user email as verified")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
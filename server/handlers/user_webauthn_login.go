@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/anfimovoleh/ms-users/config"
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+type BeginWebAuthnLoginRequest struct {
+	Email string `json:"email"`
+}
+
+func (b BeginWebAuthnLoginRequest) Validate() error {
+	return validation.ValidateStruct(&b,
+		validation.Field(&b.Email, validation.Required),
+	)
+}
+
+type BeginWebAuthnLoginHandler struct {
+	log *zap.Logger
+}
+
+func NewBeginWebAuthnLoginHandler(log *zap.Logger) *BeginWebAuthnLoginHandler {
+	return &BeginWebAuthnLoginHandler{log: log}
+}
+
+func (h BeginWebAuthnLoginHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &BeginWebAuthnLoginRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	user, err := DB(r).GetUser(request.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.beginDummyLogin(w, r, request.Email)
+			return
+		}
+
+		h.log.With(
+			zap.String("email", request.Email),
+			zap.Error(err),
+		).Error("failed to get user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", user.ID))
+
+	webAuthnUser, err := NewWebAuthnUser(r, user)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to get webauthn user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if len(webAuthnUser.WebAuthnCredentials()) == 0 {
+		h.beginDummyLogin(w, r, request.Email)
+		return
+	}
+
+	options, session, err := BeginWebAuthnLogin(WebAuthn(r).RelyingParty(), webAuthnUser)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to begin passkey login")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	sessionID, err := SaveWebAuthnSession(r, user.ID, db.WebAuthnPurposeLogin, session)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to save passkey session")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	result := WebAuthnBeginResponse{
+		SessionID: sessionID,
+		Options:   options,
+	}
+
+	if err := WriteJSON(w, http.StatusOK, result); err != nil {
+		log.With(zap.Error(err)).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
+
+// beginDummyLogin answers for unknown emails and users without passkeys the
+// way it does for a user with a passkey, so the endpoint doesn't tell the
+// accounts apart. The session is not stored, so the finish request fails
+// like for a wrong passkey.
+func (h BeginWebAuthnLoginHandler) beginDummyLogin(w http.ResponseWriter, r *http.Request, email string) {
+	dummy := &WebAuthnUser{
+		User: &db.User{Email: email},
+		credentials: []webauthn.Credential{
+			{ID: WebAuthn(r).DummyCredentialID(email)},
+		},
+	}
+
+	options, _, err := BeginWebAuthnLogin(WebAuthn(r).RelyingParty(), dummy)
+	if err != nil {
+		h.log.With(zap.Error(err)).Error("failed to begin dummy passkey login")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	sessionID, err := NewWebAuthnSessionID()
+	if err != nil {
+		h.log.With(zap.Error(err)).Error("failed to generate passkey session id")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	result := WebAuthnBeginResponse{
+		SessionID: sessionID,
+		Options:   options,
+	}
+
+	if err := WriteJSON(w, http.StatusOK, result); err != nil {
+		h.log.With(zap.Error(err)).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
+
+type FinishWebAuthnLoginHandler struct {
+	log   *zap.Logger
+	login *config.Login
+}
+
+func NewFinishWebAuthnLoginHandler(log *zap.Logger, login *config.Login) *FinishWebAuthnLoginHandler {
+	return &FinishWebAuthnLoginHandler{log: log, login: login}
+}
+
+func (h FinishWebAuthnLoginHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &WebAuthnFinishRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(request.Credential))
+	if err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	userID, session, err := TakeWebAuthnSession(r, request.SessionID, db.WebAuthnPurposeLogin)
+	if err != nil {
+		//the sessions of the dummy logins are never stored, an unknown
+		//session is answered like a wrong passkey not to tell them apart
+		if err == sql.ErrNoRows {
+			httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidPasskey)
+			return
+		}
+
+		h.log.With(zap.Error(err)).Error("failed to get passkey session")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", userID))
+
+	user, err := DB(r).GetUserByID(userID)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to get user by id")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	webAuthnUser, err := NewWebAuthnUser(r, user)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to get webauthn user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	credential, err := FinishWebAuthnLogin(WebAuthn(r).RelyingParty(), webAuthnUser, *session, parsed)
+	if err != nil {
+		log.With(zap.Error(err)).Debug("passkey login rejected")
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidPasskey)
+		return
+	}
+
+	//the sign count did not increase, the authenticator may have been cloned
+	if credential.Authenticator.CloneWarning {
+		log.Warn("passkey sign count went backwards")
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidPasskey)
+		return
+	}
+
+	err = DB(r).UseWebAuthnCredential(credential.ID, credential.Authenticator.SignCount, time.Now())
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to update passkey")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if h.login.RequireVerifiedEmail && !user.EmailVerified() {
+		httperr.ErrResponse(w, http.StatusForbidden, ErrEmailNotVerified)
+		return
+	}
+
+	//the passkey is verified by the authenticator itself, so no TOTP is asked
+	result, err := IssueTokens(r, log, user)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to issue tokens")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if err := WriteTokens(w, r, http.StatusAccepted, *result); err != nil {
+		log.With(zap.Error(err)).Error("failed to write tokens")
+		httperr.InternalServerError(w)
+		return
+	}
+}
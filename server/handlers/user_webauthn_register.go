@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"go.uber.org/zap"
+
+	"github.com/anfimovoleh/httperr"
+
+	"github.com/anfimovoleh/ms-users/db"
+	"github.com/anfimovoleh/ms-users/utils"
+)
+
+type WebAuthnCredentialResponse struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BeginWebAuthnRegistrationHandler struct {
+	log *zap.Logger
+}
+
+func NewBeginWebAuthnRegistrationHandler(log *zap.Logger) *BeginWebAuthnRegistrationHandler {
+	return &BeginWebAuthnRegistrationHandler{log: log}
+}
+
+func (h BeginWebAuthnRegistrationHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	user, _, err := utils.User(r.Context(), DB(r))
+	if err != nil {
+		h.log.With(zap.Error(err)).Error("failed to get session user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", user.ID))
+
+	webAuthnUser, err := NewWebAuthnUser(r, user)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to get webauthn user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	options, session, err := BeginWebAuthnRegistration(WebAuthn(r).RelyingParty(), webAuthnUser)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to begin passkey registration")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	sessionID, err := SaveWebAuthnSession(r, user.ID, db.WebAuthnPurposeRegistration, session)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to save passkey session")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	result := WebAuthnBeginResponse{
+		SessionID: sessionID,
+		Options:   options,
+	}
+
+	if err := WriteJSON(w, http.StatusOK, result); err != nil {
+		log.With(zap.Error(err)).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
+
+type FinishWebAuthnRegistrationHandler struct {
+	log *zap.Logger
+}
+
+func NewFinishWebAuthnRegistrationHandler(log *zap.Logger) *FinishWebAuthnRegistrationHandler {
+	return &FinishWebAuthnRegistrationHandler{log: log}
+}
+
+func (h FinishWebAuthnRegistrationHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	request := &WebAuthnFinishRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	user, _, err := utils.User(r.Context(), DB(r))
+	if err != nil {
+		h.log.With(zap.Error(err)).Error("failed to get session user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	log := h.log.With(zap.Uint64("user_id", user.ID))
+
+	userID, session, err := TakeWebAuthnSession(r, request.SessionID, db.WebAuthnPurposeRegistration)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidPasskeySession)
+			return
+		}
+
+		log.With(zap.Error(err)).Error("failed to get passkey session")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	if userID != user.ID {
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidPasskeySession)
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(request.Credential))
+	if err != nil {
+		httperr.BadRequest(w, err)
+		return
+	}
+
+	webAuthnUser, err := NewWebAuthnUser(r, user)
+	if err != nil {
+		log.With(zap.Error(err)).Error("failed to get webauthn user")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	stored, err := FinishWebAuthnRegistration(WebAuthn(r).RelyingParty(), webAuthnUser, *session, parsed)
+	if err != nil {
+		log.With(zap.Error(err)).Debug("passkey registration rejected")
+		httperr.ErrResponse(w, http.StatusUnauthorized, ErrInvalidPasskey)
+		return
+	}
+
+	if err := DB(r).CreateWebAuthnCredential(stored); err != nil {
+		log.With(zap.Error(err)).Error("failed to store passkey")
+		httperr.InternalServerError(w)
+		return
+	}
+
+	result := WebAuthnCredentialResponse{
+		ID:        base64.RawURLEncoding.EncodeToString(stored.ID),
+		CreatedAt: stored.CreatedAt,
+	}
+
+	if err := WriteJSON(w, http.StatusCreated, result); err != nil {
+		log.With(zap.Error(err)).Error("failed to serialize response")
+		httperr.InternalServerError(w)
+		return
+	}
+}
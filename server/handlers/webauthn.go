@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+// webAuthnSessionIDSize is the amount of random bytes in a passkey session id
+const webAuthnSessionIDSize = 32
+
+// WebAuthnBeginResponse holds the options for navigator.credentials and the
+// session id to be sent back together with the authenticator response
+type WebAuthnBeginResponse struct {
+	SessionID string      `json:"session_id"`
+	Options   interface{} `json:"options"`
+}
+
+// WebAuthnFinishRequest carries the PublicKeyCredential returned by the browser
+type WebAuthnFinishRequest struct {
+	SessionID  string          `json:"session_id"`
+	Credential json.RawMessage `json:"credential"`
+}
+
+func (f WebAuthnFinishRequest) Validate() error {
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.SessionID, validation.Required),
+		validation.Field(&f.Credential, validation.Required),
+	)
+}
+
+// WebAuthnUser exposes the user and the registered passkeys to the webauthn library
+type WebAuthnUser struct {
+	*db.User
+	credentials []webauthn.Credential
+}
+
+// NewWebAuthnUser loads the passkeys of the user
+func NewWebAuthnUser(r *http.Request, user *db.User) (*WebAuthnUser, error) {
+	stored, err := DB(r).GetWebAuthnCredentials(user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get passkeys")
+	}
+
+	return newWebAuthnUser(user, stored), nil
+}
+
+func newWebAuthnUser(user *db.User, stored []db.WebAuthnCredential) *WebAuthnUser {
+	credentials := make([]webauthn.Credential, 0, len(stored))
+	for _, credential := range stored {
+		credentials = append(credentials, webauthn.Credential{
+			ID:              credential.ID,
+			PublicKey:       credential.PublicKey,
+			AttestationType: credential.AttestationType,
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    credential.AAGUID,
+				SignCount: credential.SignCount,
+			},
+		})
+	}
+
+	return &WebAuthnUser{User: user, credentials: credentials}
+}
+
+func (u WebAuthnUser) WebAuthnID() []byte {
+	return []byte(strconv.FormatUint(u.ID, 10))
+}
+
+func (u WebAuthnUser) WebAuthnName() string {
+	return u.Email
+}
+
+func (u WebAuthnUser) WebAuthnDisplayName() string {
+	if u.Name != "" {
+		return u.Name
+	}
+
+	return u.Email
+}
+
+func (u WebAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u WebAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// CredentialDescriptors lists the passkeys of the user for the ceremony options
+func (u WebAuthnUser) CredentialDescriptors() []protocol.CredentialDescriptor {
+	descriptors := make([]protocol.CredentialDescriptor, 0, len(u.credentials))
+	for _, credential := range u.credentials {
+		descriptors = append(descriptors, protocol.CredentialDescriptor{
+			Type:         protocol.PublicKeyCredentialType,
+			CredentialID: credential.ID,
+		})
+	}
+
+	return descriptors
+}
+
+// BeginWebAuthnRegistration creates the options of navigator.credentials.create
+// for a new passkey of the user
+func BeginWebAuthnRegistration(
+	relyingParty *webauthn.WebAuthn, user *WebAuthnUser,
+) (*protocol.CredentialCreation, *webauthn.SessionData, error) {
+	return relyingParty.BeginRegistration(user,
+		webauthn.WithAuthenticatorSelection(relyingParty.Config.AuthenticatorSelection),
+		//do not register the same authenticator twice
+		webauthn.WithExclusions(user.CredentialDescriptors()),
+	)
+}
+
+// FinishWebAuthnRegistration verifies the attestation of the authenticator
+// and returns the passkey to be stored
+func FinishWebAuthnRegistration(
+	relyingParty *webauthn.WebAuthn, user *WebAuthnUser,
+	session webauthn.SessionData, response *protocol.ParsedCredentialCreationData,
+) (*db.WebAuthnCredential, error) {
+	credential, err := relyingParty.CreateCredential(user, session, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &db.WebAuthnCredential{
+		ID:              credential.ID,
+		UserID:          user.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		CreatedAt:       time.Now(),
+	}, nil
+}
+
+// BeginWebAuthnLogin creates the options of navigator.credentials.get
+// for the passkeys of the user
+func BeginWebAuthnLogin(
+	relyingParty *webauthn.WebAuthn, user *WebAuthnUser,
+) (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+	return relyingParty.BeginLogin(user,
+		webauthn.WithUserVerification(relyingParty.Config.AuthenticatorSelection.UserVerification),
+	)
+}
+
+// FinishWebAuthnLogin verifies the assertion of the authenticator and returns
+// the used passkey with the updated sign count
+func FinishWebAuthnLogin(
+	relyingParty *webauthn.WebAuthn, user *WebAuthnUser,
+	session webauthn.SessionData, response *protocol.ParsedCredentialAssertionData,
+) (*webauthn.Credential, error) {
+	return relyingParty.ValidateLogin(user, session, response)
+}
+
+// SaveWebAuthnSession persists the ceremony state until the finish request.
+// Only the hash of the returned session id is stored.
+func SaveWebAuthnSession(
+	r *http.Request, userID uint64, purpose db.WebAuthnPurpose, data *webauthn.SessionData,
+) (string, error) {
+	sessionID, err := NewWebAuthnSessionID()
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to serialize passkey session")
+	}
+
+	err = DB(r).CreateWebAuthnSession(&db.WebAuthnSession{
+		ID:        HashToken(sessionID),
+		UserID:    userID,
+		Purpose:   purpose,
+		Data:      string(encoded),
+		ExpiresAt: time.Now().Add(WebAuthn(r).SessionTTL),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store passkey session")
+	}
+
+	return sessionID, nil
+}
+
+// NewWebAuthnSessionID generates the id the client refers to the ceremony by
+func NewWebAuthnSessionID() (string, error) {
+	raw := make([]byte, webAuthnSessionIDSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.Wrap(err, "failed to generate passkey session id")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// TakeWebAuthnSession consumes the ceremony state, sql.ErrNoRows is returned
+// if the session is unknown, expired or was started for another purpose
+func TakeWebAuthnSession(
+	r *http.Request, sessionID string, purpose db.WebAuthnPurpose,
+) (uint64, *webauthn.SessionData, error) {
+	session, err := DB(r).TakeWebAuthnSession(HashToken(sessionID), purpose)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var data webauthn.SessionData
+	if err := json.Unmarshal([]byte(session.Data), &data); err != nil {
+		return 0, nil, errors.Wrap(err, "failed to parse passkey session")
+	}
+
+	return session.UserID, &data, nil
+}
@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/protocol/webauthncose"
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/anfimovoleh/ms-users/db"
+)
+
+const (
+	testRPID     = "example.com"
+	testRPOrigin = "https://example.com"
+)
+
+// softAuthenticator is a software ES256 authenticator which answers the
+// ceremonies the way a browser with a platform authenticator does
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credentialID := make([]byte, 16)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatal(err)
+	}
+
+	return &softAuthenticator{key: key, credentialID: credentialID}
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony protocol.CeremonyType, challenge protocol.Challenge) []byte {
+	clientData, err := json.Marshal(protocol.CollectedClientData{
+		Type:      ceremony,
+		Challenge: challenge.String(),
+		Origin:    testRPOrigin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return clientData
+}
+
+// authData is rpIdHash | flags | signCount | attestedCredentialData
+func (a *softAuthenticator) authData(t *testing.T, attested bool) []byte {
+	a.signCount++
+
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	flags := protocol.FlagUserPresent | protocol.FlagUserVerified
+
+	var data bytes.Buffer
+	data.Write(rpIDHash[:])
+	if attested {
+		flags |= protocol.FlagAttestedCredentialData
+	}
+	data.WriteByte(byte(flags))
+	_ = binary.Write(&data, binary.BigEndian, a.signCount)
+
+	if !attested {
+		return data.Bytes()
+	}
+
+	publicKey, err := cbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  1, //P-256
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data.Write(make([]byte, 16)) //AAGUID
+	_ = binary.Write(&data, binary.BigEndian, uint16(len(a.credentialID)))
+	data.Write(a.credentialID)
+	data.Write(publicKey)
+
+	return data.Bytes()
+}
+
+// create answers navigator.credentials.create with the "none" attestation
+func (a *softAuthenticator) create(t *testing.T, options *protocol.CredentialCreation) *protocol.ParsedCredentialCreationData {
+	attestationObject, err := cbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authData(t, true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"id":    encode(a.credentialID),
+		"rawId": encode(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    encode(a.clientData(t, protocol.CreateCeremony, options.Response.Challenge)),
+			"attestationObject": encode(attestationObject),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parsed
+}
+
+// get answers navigator.credentials.get signing authData | sha256(clientDataJSON)
+func (a *softAuthenticator) get(t *testing.T, options *protocol.CredentialAssertion) *protocol.ParsedCredentialAssertionData {
+	authData := a.authData(t, false)
+	clientData := a.clientData(t, protocol.AssertCeremony, options.Response.Challenge)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"id":    encode(a.credentialID),
+		"rawId": encode(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    encode(clientData),
+			"authenticatorData": encode(authData),
+			"signature":         encode(signature),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parsed
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func newTestRelyingParty(t *testing.T) *webauthn.WebAuthn {
+	relyingParty, err := webauthn.New(&webauthn.Config{
+		RPDisplayName: "ms-users",
+		RPID:          testRPID,
+		RPOrigin:      testRPOrigin,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			UserVerification: protocol.VerificationRequired,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return relyingParty
+}
+
+// register runs the registration ceremony and returns the stored passkey
+func register(t *testing.T, relyingParty *webauthn.WebAuthn, user *db.User, authenticator *softAuthenticator) *db.WebAuthnCredential {
+	options, session, err := BeginWebAuthnRegistration(relyingParty, newWebAuthnUser(user, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := FinishWebAuthnRegistration(relyingParty, newWebAuthnUser(user, nil), *session, authenticator.create(t, options))
+	if err != nil {
+		t.Fatalf("registration rejected: %v", err)
+	}
+
+	return stored
+}
+
+func TestWebAuthnRegistration(t *testing.T) {
+	relyingParty := newTestRelyingParty(t)
+	user := &db.User{ID: 42, Email: "user@example.com"}
+	authenticator := newSoftAuthenticator(t)
+
+	stored := register(t, relyingParty, user, authenticator)
+
+	if !bytes.Equal(stored.ID, authenticator.credentialID) {
+		t.Errorf("credential id = %x, want %x", stored.ID, authenticator.credentialID)
+	}
+	if stored.UserID != user.ID {
+		t.Errorf("user id = %d, want %d", stored.UserID, user.ID)
+	}
+	if stored.AttestationType != "none" {
+		t.Errorf("attestation type = %q, want none", stored.AttestationType)
+	}
+	if stored.SignCount != 1 {
+		t.Errorf("sign count = %d, want 1", stored.SignCount)
+	}
+}
+
+func TestWebAuthnRegistrationExcludesRegisteredPasskeys(t *testing.T) {
+	relyingParty := newTestRelyingParty(t)
+	user := &db.User{ID: 42, Email: "user@example.com"}
+	authenticator := newSoftAuthenticator(t)
+
+	stored := register(t, relyingParty, user, authenticator)
+
+	webAuthnUser := newWebAuthnUser(user, []db.WebAuthnCredential{*stored})
+	options, _, err := BeginWebAuthnRegistration(relyingParty, webAuthnUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	excluded := options.Response.CredentialExcludeList
+	if len(excluded) != 1 || !bytes.Equal(excluded[0].CredentialID, stored.ID) {
+		t.Errorf("exclude list = %v, want the registered passkey", excluded)
+	}
+}
+
+func TestWebAuthnRegistrationRejectsOtherChallenge(t *testing.T) {
+	relyingParty := newTestRelyingParty(t)
+	user := &db.User{ID: 42, Email: "user@example.com"}
+	authenticator := newSoftAuthenticator(t)
+
+	options, _, err := BeginWebAuthnRegistration(relyingParty, newWebAuthnUser(user, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the response is checked against the state of another ceremony
+	_, session, err := BeginWebAuthnRegistration(relyingParty, newWebAuthnUser(user, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = FinishWebAuthnRegistration(relyingParty, newWebAuthnUser(user, nil), *session, authenticator.create(t, options))
+	if err == nil {
+		t.Error("registration with a foreign challenge accepted")
+	}
+}
+
+func TestWebAuthnLogin(t *testing.T) {
+	relyingParty := newTestRelyingParty(t)
+	user := &db.User{ID: 42, Email: "user@example.com"}
+	authenticator := newSoftAuthenticator(t)
+
+	stored := register(t, relyingParty, user, authenticator)
+	webAuthnUser := newWebAuthnUser(user, []db.WebAuthnCredential{*stored})
+
+	options, session, err := BeginWebAuthnLogin(relyingParty, webAuthnUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := options.Response.AllowedCredentials
+	if len(allowed) != 1 || !bytes.Equal(allowed[0].CredentialID, stored.ID) {
+		t.Errorf("allowed credentials = %v, want the registered passkey", allowed)
+	}
+
+	credential, err := FinishWebAuthnLogin(relyingParty, webAuthnUser, *session, authenticator.get(t, options))
+	if err != nil {
+		t.Fatalf("login rejected: %v", err)
+	}
+
+	if !bytes.Equal(credential.ID, stored.ID) {
+		t.Errorf("credential id = %x, want %x", credential.ID, stored.ID)
+	}
+	if credential.Authenticator.SignCount != 2 {
+		t.Errorf("sign count = %d, want 2", credential.Authenticator.SignCount)
+	}
+	if credential.Authenticator.CloneWarning {
+		t.Error("clone warning for an increased sign count")
+	}
+}
+
+func TestWebAuthnLoginRejectsOtherKey(t *testing.T) {
+	relyingParty := newTestRelyingParty(t)
+	user := &db.User{ID: 42, Email: "user@example.com"}
+	authenticator := newSoftAuthenticator(t)
+
+	stored := register(t, relyingParty, user, authenticator)
+	webAuthnUser := newWebAuthnUser(user, []db.WebAuthnCredential{*stored})
+
+	options, session, err := BeginWebAuthnLogin(relyingParty, webAuthnUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//same credential id, but the assertion is signed by another key
+	impostor := newSoftAuthenticator(t)
+	impostor.credentialID = authenticator.credentialID
+
+	if _, err := FinishWebAuthnLogin(relyingParty, webAuthnUser, *session, impostor.get(t, options)); err == nil {
+		t.Error("assertion signed by another key accepted")
+	}
+}
+
+func TestWebAuthnLoginWarnsOnClonedAuthenticator(t *testing.T) {
+	relyingParty := newTestRelyingParty(t)
+	user := &db.User{ID: 42, Email: "user@example.com"}
+	authenticator := newSoftAuthenticator(t)
+
+	stored := register(t, relyingParty, user, authenticator)
+	//the passkey was already used with a higher sign count
+	stored.SignCount = 10
+	webAuthnUser := newWebAuthnUser(user, []db.WebAuthnCredential{*stored})
+
+	options, session, err := BeginWebAuthnLogin(relyingParty, webAuthnUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credential, err := FinishWebAuthnLogin(relyingParty, webAuthnUser, *session, authenticator.get(t, options))
+	if err != nil {
+		t.Fatalf("login rejected: %v", err)
+	}
+
+	if !credential.Authenticator.CloneWarning {
+		t.Error("no clone warning for a sign count going backwards")
+	}
+}
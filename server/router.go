@@ -6,10 +6,12 @@ import (
 	chiwares "github.com/anfimovoleh/go-chi-middlewares"
 
 	"github.com/anfimovoleh/ms-users/config"
+	"github.com/anfimovoleh/ms-users/signing"
 
 	"github.com/anfimovoleh/ms-users/server/handlers"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/cors"
+	"github.com/go-chi/jwtauth"
 )
 
 func Router(
@@ -17,9 +19,17 @@ func Router(
 ) chi.Router {
 	router := chi.NewRouter()
 
+	allowedOrigins := []string{"*", "https://localhost:3000"}
+	//credentialed requests of any origin would carry the session cookies,
+	//so only the web app may send them in the cookie mode
+	if cfg.SessionCookie().Enabled {
+		website := cfg.WebsiteURL()
+		allowedOrigins = []string{website.Scheme + "://" + website.Host}
+	}
+
 	cors := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*", "https://localhost:3000"},
-		AllowedMethods:   []string{"*", "GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedOrigins:   allowedOrigins,
+		AllowedMethods:   []string{"*", "GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*", "Accept", "Authorization", "Content-Type", "X-CSRF-Token", "x-auth"},
 		ExposedHeaders:   []string{"*", "Link"},
 		AllowCredentials: true,
@@ -31,6 +41,10 @@ func Router(
 		cfg.Log().Fatal("failed to get URL")
 	}
 
+	if len(cfg.HTTP().TrustedProxyNetworks()) > 0 {
+		router.Use(handlers.RealIP(cfg.HTTP().TrustedProxyNetworks()))
+	}
+
 	router.Use(
 		cors.Handler,
 		chiwares.Logger(cfg.Log(), cfg.HTTP().ReqDurThreshold),
@@ -40,16 +54,87 @@ func Router(
 			handlers.CtxWebApp(cfg.WebsiteURL()),
 			handlers.CtxDB(cfg.DB()),
 			handlers.CtxJWT(cfg.JWT()),
+			handlers.CtxTokens(cfg.Tokens()),
+			handlers.CtxAuthentication(cfg.Authentication()),
+			handlers.CtxMFA(cfg.MFA()),
+			handlers.CtxWebAuthn(cfg.WebAuthn()),
+			handlers.CtxPassword(cfg.Password()),
+			handlers.CtxSessionCookie(cfg.SessionCookie()),
 		),
 	)
 
+	//the session cookie is only read in the cookie mode, where it is
+	//protected against CSRF
+	tokenFinders := []func(r *http.Request) string{jwtauth.TokenFromHeader}
+	if cfg.SessionCookie().Enabled {
+		tokenFinders = append(tokenFinders, handlers.TokenFromCookie(cfg.SessionCookie()))
+		router.Use(handlers.CSRF(cfg.SessionCookie()))
+	}
+
 	router.Route("/user", func(router chi.Router) {
-		router.Post("/login", handlers.NewLoginHandler(cfg.Log()).Handle)
-		router.Post("/signup", handlers.NewSignupHandler(cfg.Log()).Handle)
-		router.Put("/new_password", handlers.NewNewPasswordHandler(cfg.Log()).Handle)
-		router.Post("/reset_password", handlers.NewResetPasswordHandler(cfg.Log()).Handle)
+		limit := handlers.RateLimit(cfg.Log(), cfg.RateLimit())
+
+		router.With(limit("login")).Post("/login", handlers.NewLoginHandler(cfg.Log(), cfg.Login(), cfg.Lockout()).Handle)
+		if cfg.MFA().Enabled() {
+			router.With(limit("login/mfa")).Post("/login/mfa", handlers.NewLoginMFAHandler(cfg.Log(), cfg.Lockout()).Handle)
+		}
+		router.With(limit("login/magic")).Post("/login/magic", handlers.NewMagicLinkHandler(cfg.Log()).Handle)
+		router.With(limit("login/magic/verify")).Post("/login/magic/verify", handlers.NewMagicLinkLoginHandler(cfg.Log()).Handle)
+		router.With(limit("login/not_me")).Post("/login/not_me", handlers.NewNotMeHandler(cfg.Log()).Handle)
+		router.With(limit("signup")).Post("/signup", handlers.NewSignupHandler(cfg.Log()).Handle)
+		router.With(limit("verify")).Post("/verify", handlers.NewVerifyEmailHandler(cfg.Log()).Handle)
+		router.With(limit("verify/resend")).Post("/verify/resend", handlers.NewResendVerificationHandler(cfg.Log()).Handle)
+		router.With(limit("new_password")).Put("/new_password", handlers.NewNewPasswordHandler(cfg.Log()).Handle)
+		router.With(limit("reset_password")).Post("/reset_password", handlers.NewResetPasswordHandler(cfg.Log()).Handle)
+		router.With(limit("reset_password/resend")).Post("/reset_password/resend", handlers.NewResendResetPasswordHandler(cfg.Log()).Handle)
+		router.With(limit("email/confirm")).Post("/email/confirm", handlers.NewConfirmEmailHandler(cfg.Log()).Handle)
+		router.With(limit("email/revert")).Post("/email/revert", handlers.NewRevertEmailHandler(cfg.Log()).Handle)
+		router.With(limit("token/refresh")).Post("/token/refresh", handlers.NewRefreshTokenHandler(cfg.Log()).Handle)
+		router.With(limit("webauthn/login/begin")).Post("/webauthn/login/begin", handlers.NewBeginWebAuthnLoginHandler(cfg.Log()).Handle)
+		router.With(limit("webauthn/login/finish")).Post("/webauthn/login/finish", handlers.NewFinishWebAuthnLoginHandler(cfg.Log(), cfg.Login()).Handle)
+
+		router.Group(func(router chi.Router) {
+			router.Use(
+				signing.Verifier(cfg.JWT(), tokenFinders...),
+				handlers.Authenticator(cfg.Log()),
+			)
+
+			router.Post("/logout", handlers.NewLogoutHandler(cfg.Log()).Handle)
+			router.Post("/logout/all", handlers.NewLogoutAllHandler(cfg.Log()).Handle)
+
+			router.Get("/me", handlers.NewGetMeHandler(cfg.Log()).Handle)
+			router.Patch("/me", handlers.NewUpdateMeHandler(cfg.Log()).Handle)
+			router.Post("/me/email", handlers.NewChangeEmailHandler(cfg.Log(), cfg.Lockout()).Handle)
+			router.Put("/me/password", handlers.NewChangePasswordHandler(cfg.Log(), cfg.Lockout()).Handle)
+
+			router.Get("/me/sessions", handlers.NewGetSessionsHandler(cfg.Log()).Handle)
+			router.Delete("/me/sessions/{id}", handlers.NewRevokeSessionHandler(cfg.Log()).Handle)
+			router.Get("/me/login-history", handlers.NewGetLoginHistoryHandler(cfg.Log()).Handle)
+
+			if cfg.MFA().Enabled() {
+				router.Post("/me/mfa/totp", handlers.NewEnrollTOTPHandler(cfg.Log()).Handle)
+				router.Post("/me/mfa/totp/confirm", handlers.NewConfirmTOTPHandler(cfg.Log()).Handle)
+				router.Post("/me/mfa/totp/disable", handlers.NewDisableTOTPHandler(cfg.Log()).Handle)
+				router.Post("/me/mfa/recovery_codes", handlers.NewRegenerateRecoveryCodesHandler(cfg.Log()).Handle)
+			}
+
+			router.Post("/webauthn/register/begin", handlers.NewBeginWebAuthnRegistrationHandler(cfg.Log()).Handle)
+			router.Post("/webauthn/register/finish", handlers.NewFinishWebAuthnRegistrationHandler(cfg.Log()).Handle)
+		})
 	})
 
+	router.Route("/admin", func(router chi.Router) {
+		router.Use(
+			signing.Verifier(cfg.JWT(), tokenFinders...),
+			handlers.Authenticator(cfg.Log()),
+			handlers.AdminOnly(cfg.Log()),
+		)
+
+		router.Get("/login-history", handlers.NewAdminLoginHistoryHandler(cfg.Log()).Handle)
+	})
+
+	router.Get("/.well-known/jwks.json", handlers.NewJWKSHandler(cfg.Log()).Handle)
+
 	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte(`{"api":"ms-users"}`))
 	})
@@ -0,0 +1,149 @@
+package signing
+
+import (
+	"sync"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jws"
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrUnknownKey        = errors.New("token is signed with unknown key")
+	ErrAlgorithmMismatch = errors.New("token algorithm does not match the key")
+)
+
+// JWTAuth signs access tokens with the signing key and verifies them with any
+// of the known keys picked by the kid header.
+type JWTAuth struct {
+	mu      sync.RWMutex
+	signing *Key
+	keys    map[string]*Key
+}
+
+// New creates JWTAuth, the signing key is always accepted for verification
+func New(signing *Key, verification ...*Key) (*JWTAuth, error) {
+	ja := &JWTAuth{}
+	if err := ja.Replace(signing, verification...); err != nil {
+		return nil, err
+	}
+
+	return ja, nil
+}
+
+// Replace swaps the keys, it's safe to call while tokens are being processed
+func (ja *JWTAuth) Replace(signing *Key, verification ...*Key) error {
+	if signing == nil || signing.Private == nil {
+		return errors.New("signing key is required")
+	}
+
+	keys := map[string]*Key{signing.ID: signing}
+	for _, key := range verification {
+		if _, ok := keys[key.ID]; ok {
+			return errors.Errorf("duplicated key %s", key.ID)
+		}
+		keys[key.ID] = key
+	}
+
+	ja.mu.Lock()
+	defer ja.mu.Unlock()
+
+	ja.signing = signing
+	ja.keys = keys
+
+	return nil
+}
+
+func (ja *JWTAuth) signingKey() *Key {
+	ja.mu.RLock()
+	defer ja.mu.RUnlock()
+
+	return ja.signing
+}
+
+func (ja *JWTAuth) key(id string) (*Key, bool) {
+	ja.mu.RLock()
+	defer ja.mu.RUnlock()
+
+	key, ok := ja.keys[id]
+	return key, ok
+}
+
+// Encode signs the claims, the signature is compatible with jwtauth.JWTAuth.Encode
+func (ja *JWTAuth) Encode(claims map[string]interface{}) (jwt.Token, string, error) {
+	token := jwt.New()
+	for name, value := range claims {
+		if err := token.Set(name, value); err != nil {
+			return nil, "", errors.Wrapf(err, "failed to set %s claim", name)
+		}
+	}
+
+	signing := ja.signingKey()
+
+	headers := jws.NewHeaders()
+	if signing.ID != "" {
+		if err := headers.Set(jws.KeyIDKey, signing.ID); err != nil {
+			return nil, "", errors.Wrap(err, "failed to set kid header")
+		}
+	}
+
+	signed, err := jwt.Sign(token, signing.Algorithm, signing.Private, jwt.WithHeaders(headers))
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to sign token")
+	}
+
+	return token, string(signed), nil
+}
+
+// Decode verifies the signature of the token, claims are not validated
+func (ja *JWTAuth) Decode(tokenString string) (jwt.Token, error) {
+	message, err := jws.ParseString(tokenString)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse token")
+	}
+
+	if len(message.Signatures()) != 1 {
+		return nil, errors.New("token must have exactly one signature")
+	}
+
+	headers := message.Signatures()[0].ProtectedHeaders()
+
+	key, ok := ja.key(headers.KeyID())
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if headers.Algorithm() != key.Algorithm {
+		return nil, ErrAlgorithmMismatch
+	}
+
+	return jwt.ParseString(tokenString, jwt.WithVerify(key.Algorithm, key.Public))
+}
+
+// PublicKeys returns the JWK set of the asymmetric keys the tokens may be signed with
+func (ja *JWTAuth) PublicKeys() (jwk.Set, error) {
+	ja.mu.RLock()
+	defer ja.mu.RUnlock()
+
+	set := jwk.NewSet()
+	for _, key := range ja.keys {
+		if key.Symmetric() {
+			continue
+		}
+
+		public, err := key.JWK()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to publish key %s", key.ID)
+		}
+		set.Add(public)
+	}
+
+	return set, nil
+}
+
+// Algorithm returns the algorithm new tokens are signed with
+func (ja *JWTAuth) Algorithm() jwa.SignatureAlgorithm {
+	return ja.signingKey().Algorithm
+}
@@ -0,0 +1,174 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/pkg/errors"
+)
+
+// Key is a key the access tokens are signed or verified with
+type Key struct {
+	// ID is put into the kid header of the signed tokens
+	ID        string
+	Algorithm jwa.SignatureAlgorithm
+	// Private is nil for keys which are only used for verification
+	Private interface{}
+	Public  interface{}
+}
+
+// NewHMACKey creates a symmetric key, the secret is used both to sign and
+// to verify, so it's never published
+func NewHMACKey(algorithm jwa.SignatureAlgorithm, secret []byte) (*Key, error) {
+	switch algorithm {
+	case jwa.HS256, jwa.HS384, jwa.HS512:
+	default:
+		return nil, errors.Errorf("unsupported HMAC algorithm %s", algorithm)
+	}
+
+	if len(secret) == 0 {
+		return nil, errors.New("empty HMAC secret")
+	}
+
+	return &Key{
+		Algorithm: algorithm,
+		Private:   secret,
+		Public:    secret,
+	}, nil
+}
+
+// NewKey wraps an RSA, ECDSA or Ed25519 private key. The algorithm is derived
+// from the key type and the key ID is the RFC 7638 thumbprint of the public key.
+func NewKey(private crypto.Signer) (*Key, error) {
+	algorithm, err := algorithmOf(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := jwk.New(private.Public())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build public key")
+	}
+
+	thumbprint, err := public.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute key thumbprint")
+	}
+
+	return &Key{
+		ID:        base64.RawURLEncoding.EncodeToString(thumbprint),
+		Algorithm: algorithm,
+		Private:   private,
+		Public:    private.Public(),
+	}, nil
+}
+
+// ParsePrivateKeyPEM reads a PKCS #8, PKCS #1 or SEC 1 encoded private key
+func ParsePrivateKeyPEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var (
+		private interface{}
+		err     error
+	)
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, errors.Errorf("unsupported PEM block %s", block.Type)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key")
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("unsupported private key %T", private)
+	}
+
+	return NewKey(signer)
+}
+
+// LoadPrivateKeyFile reads a PEM encoded private key from the file
+func LoadPrivateKeyFile(path string) (*Key, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+
+	key, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", path)
+	}
+
+	return key, nil
+}
+
+// Symmetric reports whether the key can't be published
+func (k Key) Symmetric() bool {
+	_, ok := k.Public.([]byte)
+	return ok
+}
+
+// JWK returns the public part of the key in the JWK form
+func (k Key) JWK() (jwk.Key, error) {
+	if k.Symmetric() {
+		return nil, errors.New("symmetric keys can't be published")
+	}
+
+	key, err := jwk.New(k.Public)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build public key")
+	}
+
+	for name, value := range map[string]interface{}{
+		jwk.KeyIDKey:     k.ID,
+		jwk.AlgorithmKey: k.Algorithm,
+		jwk.KeyUsageKey:  jwk.ForSignature,
+	} {
+		if err := key.Set(name, value); err != nil {
+			return nil, errors.Wrapf(err, "failed to set %s", name)
+		}
+	}
+
+	return key, nil
+}
+
+func algorithmOf(public crypto.PublicKey) (jwa.SignatureAlgorithm, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return jwa.RS256, nil
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return jwa.ES256, nil
+		case elliptic.P384():
+			return jwa.ES384, nil
+		case elliptic.P521():
+			return jwa.ES512, nil
+		default:
+			return "", errors.Errorf("unsupported curve %s", key.Curve.Params().Name)
+		}
+	case ed25519.PublicKey:
+		return jwa.EdDSA, nil
+	default:
+		return "", errors.Errorf("unsupported public key %T", public)
+	}
+}
@@ -0,0 +1,328 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/pkg/errors"
+)
+
+const (
+	manifestFile = "manifest.json"
+	lockFile     = "manifest.lock"
+	rsaKeySize   = 2048
+)
+
+// JWKSMaxAge is the time the verifiers may cache the published keys for
+const JWKSMaxAge = 5 * time.Minute
+
+// KeystoreManifest lists the keys of the keystore. Only the active key signs
+// new tokens, the next key is published ahead of its activation so the
+// verifiers learn it before it's used, and the retired ones are kept to
+// verify the tokens they signed.
+type KeystoreManifest struct {
+	Active string          `json:"active"`
+	Next   string          `json:"next,omitempty"`
+	Keys   []KeystoreEntry `json:"keys"`
+}
+
+type KeystoreEntry struct {
+	ID        string    `json:"kid"`
+	CreatedAt time.Time `json:"created_at"`
+	// ActivatesAt is the time the key starts signing tokens, the next key
+	// takes over from the active one on its own once it's reached
+	ActivatesAt time.Time  `json:"activates_at"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty"`
+}
+
+// Keystore keeps the keys in a directory, every key is stored as <kid>.pem
+// next to the manifest
+type Keystore struct {
+	dir string
+}
+
+func NewKeystore(dir string) *Keystore {
+	return &Keystore{dir: dir}
+}
+
+// Keys loads the active key and the ones only used for verification, i.e.
+// the next and the retired keys
+func (s *Keystore) Keys() (*Key, []*Key, error) {
+	return s.keys(time.Now().UTC())
+}
+
+func (s *Keystore) keys(now time.Time) (*Key, []*Key, error) {
+	manifest, err := s.manifest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if manifest.Active == "" {
+		return nil, nil, errors.Errorf("no active key in %s, run keys rotate", s.dir)
+	}
+
+	activeID := manifest.Active
+	if next := manifest.entry(manifest.Next); next != nil && !next.ActivatesAt.After(now) {
+		activeID = next.ID
+	}
+
+	var (
+		active  *Key
+		retired []*Key
+	)
+
+	for _, entry := range manifest.Keys {
+		key, err := LoadPrivateKeyFile(s.keyPath(entry.ID))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if key.ID != entry.ID {
+			return nil, nil, errors.Errorf("key %s does not match its thumbprint %s", entry.ID, key.ID)
+		}
+
+		if entry.ID == activeID {
+			active = key
+			continue
+		}
+		retired = append(retired, key)
+	}
+
+	if active == nil {
+		return nil, nil, errors.Errorf("active key %s is not listed in %s", activeID, s.dir)
+	}
+
+	return active, retired, nil
+}
+
+// Load creates JWTAuth from the keystore
+func (s *Keystore) Load() (*JWTAuth, error) {
+	active, retired, err := s.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	return New(active, retired...)
+}
+
+// Reload replaces the keys of ja with the current content of the keystore
+func (s *Keystore) Reload(ja *JWTAuth) error {
+	active, retired, err := s.Keys()
+	if err != nil {
+		return err
+	}
+
+	return ja.Replace(active, retired...)
+}
+
+// Rotate generates a new key and promotes it in delay, so every verifier
+// knows it before it signs tokens. Until then it's published as the next key,
+// the services switch to it on their own. The first key of the keystore is
+// activated right away, as there are no verifiers to learn it yet.
+//
+// The key it replaces is retired at the activation, and keys retired longer
+// than retention ago are removed, as every token they signed has already
+// expired.
+func (s *Keystore) Rotate(algorithm jwa.SignatureAlgorithm, delay, retention time.Duration) (*Key, time.Time, error) {
+	return s.rotate(algorithm, delay, retention, time.Now().UTC())
+}
+
+func (s *Keystore) rotate(algorithm jwa.SignatureAlgorithm, delay, retention time.Duration, now time.Time) (*Key, time.Time, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer unlock()
+
+	manifest, err := s.manifest()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	//the previous rotation has to complete first
+	if next := manifest.entry(manifest.Next); next != nil {
+		if wait := next.ActivatesAt.Sub(now); wait > 0 {
+			return nil, time.Time{}, errors.Errorf("key %s is activated in %s, rotate after that", next.ID, wait.Round(time.Second))
+		}
+
+		if active := manifest.entry(manifest.Active); active != nil {
+			retiredAt := next.ActivatesAt
+			active.RetiredAt = &retiredAt
+		}
+
+		manifest.Active = manifest.Next
+		manifest.Next = ""
+	}
+
+	keys := make([]KeystoreEntry, 0, len(manifest.Keys)+1)
+	var expired []string
+
+	for _, entry := range manifest.Keys {
+		if entry.RetiredAt != nil && entry.RetiredAt.Add(retention).Before(now) {
+			expired = append(expired, entry.ID)
+			continue
+		}
+		keys = append(keys, entry)
+	}
+
+	key, encoded, err := GenerateKey(algorithm)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if err := ioutil.WriteFile(s.keyPath(key.ID), encoded, 0600); err != nil {
+		return nil, time.Time{}, errors.Wrapf(err, "failed to write key %s", key.ID)
+	}
+
+	activatesAt := now
+	if manifest.Active == "" {
+		manifest.Active = key.ID
+	} else {
+		manifest.Next = key.ID
+		activatesAt = now.Add(delay)
+	}
+
+	manifest.Keys = append(keys, KeystoreEntry{ID: key.ID, CreatedAt: now, ActivatesAt: activatesAt})
+
+	if err := s.writeManifest(manifest); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	//keys are removed only after the manifest stops referencing them
+	for _, id := range expired {
+		if err := os.Remove(s.keyPath(id)); err != nil && !os.IsNotExist(err) {
+			return nil, time.Time{}, errors.Wrapf(err, "failed to remove expired key %s", id)
+		}
+	}
+
+	return key, activatesAt, nil
+}
+
+// lock guards the manifest against concurrent keys commands, the returned
+// function releases the lock
+func (s *Keystore) lock() (func(), error) {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s", s.dir)
+	}
+
+	path := filepath.Join(s.dir, lockFile)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, errors.Errorf("keystore is locked by another keys command, remove %s if none is running", path)
+		}
+		return nil, errors.Wrap(err, "failed to lock keystore")
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(path)
+		return nil, errors.Wrap(err, "failed to lock keystore")
+	}
+
+	return func() {
+		_ = os.Remove(path)
+	}, nil
+}
+
+// entry returns the listed key with the id or nil
+func (m *KeystoreManifest) entry(id string) *KeystoreEntry {
+	if id == "" {
+		return nil
+	}
+
+	for i := range m.Keys {
+		if m.Keys[i].ID == id {
+			return &m.Keys[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *Keystore) keyPath(id string) string {
+	return filepath.Join(s.dir, id+".pem")
+}
+
+func (s *Keystore) manifest() (*KeystoreManifest, error) {
+	manifest := &KeystoreManifest{}
+
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, manifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+		return nil, errors.Wrap(err, "failed to read keystore manifest")
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to parse keystore manifest")
+	}
+
+	return manifest, nil
+}
+
+func (s *Keystore) writeManifest(manifest *KeystoreManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize keystore manifest")
+	}
+
+	//replace the manifest atomically, so running services never read a partial file
+	path := filepath.Join(s.dir, manifestFile)
+	if err := ioutil.WriteFile(path+".tmp", data, 0600); err != nil {
+		return errors.Wrap(err, "failed to write keystore manifest")
+	}
+
+	return errors.Wrap(os.Rename(path+".tmp", path), "failed to replace keystore manifest")
+}
+
+// GenerateKey creates a private key for the algorithm and returns it along
+// with its PKCS #8 PEM encoding
+func GenerateKey(algorithm jwa.SignatureAlgorithm) (*Key, []byte, error) {
+	var (
+		private crypto.Signer
+		err     error
+	)
+
+	switch algorithm {
+	case jwa.EdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case jwa.ES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case jwa.ES384:
+		private, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case jwa.ES512:
+		private, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case jwa.RS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeySize)
+	default:
+		return nil, nil, errors.Errorf("unsupported algorithm %s", algorithm)
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate key")
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to encode key")
+	}
+
+	key, err := NewKey(private)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
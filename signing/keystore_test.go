@@ -0,0 +1,148 @@
+package signing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+)
+
+func TestKeystoreRotation(t *testing.T) {
+	keystore := NewKeystore(t.TempDir())
+	now := time.Now().UTC()
+
+	first, activatesAt, err := keystore.rotate(jwa.ES256, time.Hour, time.Hour, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the first key has no verifiers to wait for
+	if !activatesAt.Equal(now) {
+		t.Errorf("first key activates at %s, want %s", activatesAt, now)
+	}
+	active, _, err := keystore.keys(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active.ID != first.ID {
+		t.Fatalf("active key = %s, want the first key %s", active.ID, first.ID)
+	}
+
+	next, activatesAt, err := keystore.rotate(jwa.ES256, time.Hour, time.Hour, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(time.Hour); !activatesAt.Equal(want) {
+		t.Errorf("next key activates at %s, want %s", activatesAt, want)
+	}
+
+	//the next key is published for verification only until the delay passes
+	active, verification, err := keystore.keys(now.Add(time.Hour - time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active.ID != first.ID {
+		t.Errorf("active key = %s, want %s until the activation", active.ID, first.ID)
+	}
+	if len(verification) != 1 || verification[0].ID != next.ID {
+		t.Errorf("verification keys = %v, want the next key %s", verification, next.ID)
+	}
+
+	if _, _, err := keystore.rotate(jwa.ES256, time.Hour, time.Hour, now.Add(time.Minute)); err == nil {
+		t.Error("rotated before the next key was activated")
+	}
+
+	//then it's promoted without another command
+	active, verification, err = keystore.keys(now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active.ID != next.ID {
+		t.Errorf("active key = %s, want %s after the activation", active.ID, next.ID)
+	}
+	if len(verification) != 1 || verification[0].ID != first.ID {
+		t.Errorf("verification keys = %v, want the retired key %s", verification, first.ID)
+	}
+
+	//the next rotation records the promotion
+	third, _, err := keystore.rotate(jwa.ES256, time.Hour, time.Hour, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := keystore.manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Active != next.ID || manifest.Next != third.ID {
+		t.Errorf("manifest active %s and next %s, want %s and %s", manifest.Active, manifest.Next, next.ID, third.ID)
+	}
+	if retired := manifest.entry(first.ID); retired == nil || retired.RetiredAt == nil || !retired.RetiredAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("first key entry = %+v, want it retired at the activation of the next key", retired)
+	}
+}
+
+func TestKeystoreRemovesExpiredKeys(t *testing.T) {
+	dir := t.TempDir()
+	keystore := NewKeystore(dir)
+	now := time.Now().UTC()
+
+	first, _, err := keystore.rotate(jwa.ES256, time.Minute, time.Hour, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the first key is retired when the second one activates
+	if _, _, err := keystore.rotate(jwa.ES256, time.Minute, time.Hour, now); err != nil {
+		t.Fatal(err)
+	}
+
+	//still within the retention
+	now = now.Add(time.Hour)
+	if _, _, err := keystore.rotate(jwa.ES256, time.Minute, time.Hour, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, first.ID+".pem")); err != nil {
+		t.Errorf("retired key file is removed within the retention: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, _, err := keystore.rotate(jwa.ES256, time.Minute, time.Hour, now); err != nil {
+		t.Fatal(err)
+	}
+
+	_, verification, err := keystore.keys(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range verification {
+		if key.ID == first.ID {
+			t.Error("expired key is still published")
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, first.ID+".pem")); !os.IsNotExist(err) {
+		t.Errorf("expired key file is kept: %v", err)
+	}
+}
+
+func TestKeystoreLock(t *testing.T) {
+	keystore := NewKeystore(t.TempDir())
+
+	unlock, err := keystore.lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := keystore.Rotate(jwa.ES256, time.Minute, time.Hour); err == nil {
+		t.Error("rotated a locked keystore")
+	}
+
+	unlock()
+
+	if _, _, err := keystore.Rotate(jwa.ES256, time.Minute, time.Hour); err != nil {
+		t.Errorf("rotate after unlock: %v", err)
+	}
+}
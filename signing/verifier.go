@@ -0,0 +1,54 @@
+package signing
+
+import (
+	"net/http"
+
+	"github.com/go-chi/jwtauth"
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+// Verifier is the jwtauth.Verifier counterpart for JWTAuth, the verified token
+// and error are put into the context the same way, so jwtauth.FromContext
+// can be used to read them. The token is looked up by findTokenFns in order,
+// the Authorization header is used if none are given.
+func Verifier(ja *JWTAuth, findTokenFns ...func(r *http.Request) string) func(http.Handler) http.Handler {
+	if len(findTokenFns) == 0 {
+		findTokenFns = []func(r *http.Request) string{jwtauth.TokenFromHeader}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, err := VerifyRequest(ja, r, findTokenFns...)
+			ctx := jwtauth.NewContext(r.Context(), token, err)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func VerifyRequest(ja *JWTAuth, r *http.Request, findTokenFns ...func(r *http.Request) string) (jwt.Token, error) {
+	var tokenString string
+	for _, fn := range findTokenFns {
+		if tokenString = fn(r); tokenString != "" {
+			break
+		}
+	}
+
+	if tokenString == "" {
+		return nil, jwtauth.ErrNoTokenFound
+	}
+
+	return VerifyToken(ja, tokenString)
+}
+
+func VerifyToken(ja *JWTAuth, tokenString string) (jwt.Token, error) {
+	token, err := ja.Decode(tokenString)
+	if err != nil {
+		return nil, jwtauth.ErrorReason(err)
+	}
+
+	if err := jwt.Validate(token); err != nil {
+		return token, jwtauth.ErrorReason(err)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// Claim names of the access tokens issued by the service. Registered claims
+// follow RFC 7519, the rest are private claims other services may rely on.
+const (
+	// ClaimSubject is the user ID formatted as a decimal string
+	ClaimSubject = "sub"
+	// ClaimIssuer identifies the service which issued the token
+	ClaimIssuer = "iss"
+	// ClaimAudience lists the services the token is intended for
+	ClaimAudience  = "aud"
+	ClaimIssuedAt  = "iat"
+	ClaimNotBefore = "nbf"
+	ClaimExpiresAt = "exp"
+	// ClaimID is unique per token and is used to revoke it
+	ClaimID            = "jti"
+	ClaimEmail         = "email"
+	ClaimEmailVerified = "email_verified"
+	// ClaimSession is the login session the token was issued for
+	ClaimSession = "sid"
+	// ClaimGeneration is the token generation of the user at issuing time,
+	// tokens of older generations are rejected
+	ClaimGeneration = "gen"
+)
+
+// Claims is the claim set every access token carries
+type Claims struct {
+	UserID        uint64
+	Issuer        string
+	Audience      []string
+	IssuedAt      time.Time
+	NotBefore     time.Time
+	ExpiresAt     time.Time
+	ID            string
+	Email         string
+	EmailVerified bool
+	Session       string
+	Generation    int64
+}
+
+// Map returns the claims in the form accepted by jwtauth.JWTAuth.Encode
+func (c Claims) Map() map[string]interface{} {
+	return map[string]interface{}{
+		ClaimSubject:       strconv.FormatUint(c.UserID, 10),
+		ClaimIssuer:        c.Issuer,
+		ClaimAudience:      c.Audience,
+		ClaimIssuedAt:      c.IssuedAt,
+		ClaimNotBefore:     c.NotBefore,
+		ClaimExpiresAt:     c.ExpiresAt,
+		ClaimID:            c.ID,
+		ClaimEmail:         c.Email,
+		ClaimEmailVerified: c.EmailVerified,
+		ClaimSession:       c.Session,
+		ClaimGeneration:    c.Generation,
+	}
+}
+
+// HasAudience reports whether the token is intended for the audience
+func (c Claims) HasAudience(audience string) bool {
+	for _, aud := range c.Audience {
+		if aud == audience {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseClaims reads the claims of a decoded token
+func ParseClaims(claims map[string]interface{}) (*Claims, error) {
+	userID, err := strconv.ParseUint(cast.ToString(claims[ClaimSubject]), 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid sub claim")
+	}
+
+	id := cast.ToString(claims[ClaimID])
+	if id == "" {
+		return nil, errors.New("missing jti claim")
+	}
+
+	generation, err := cast.ToInt64E(claims[ClaimGeneration])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid gen claim")
+	}
+
+	return &Claims{
+		UserID:        userID,
+		Issuer:        cast.ToString(claims[ClaimIssuer]),
+		Audience:      cast.ToStringSlice(claims[ClaimAudience]),
+		IssuedAt:      cast.ToTime(claims[ClaimIssuedAt]),
+		NotBefore:     cast.ToTime(claims[ClaimNotBefore]),
+		ExpiresAt:     cast.ToTime(claims[ClaimExpiresAt]),
+		ID:            id,
+		Email:         cast.ToString(claims[ClaimEmail]),
+		EmailVerified: cast.ToBool(claims[ClaimEmailVerified]),
+		Session:       cast.ToString(claims[ClaimSession]),
+		Generation:    generation,
+	}, nil
+}
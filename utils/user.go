@@ -2,13 +2,14 @@ package utils
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
 	"github.com/anfimovoleh/ms-users/db"
 
 	"github.com/pkg/errors"
 
-	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/jwtauth"
 )
 
 func ErrResponse(code int, err error) []byte {
@@ -20,21 +21,26 @@ var (
 	ErrInvalidEmailOrPassword = errors.New("invalid email or password")
 )
 
-func User(ctx context.Context, db *db.DB) (*db.User, string, error) {
-	token, claims, _ := jwtauth.FromContext(ctx)
-	userEmail, ok := claims["user_email"].(string)
-	if !ok {
-		return nil, "", ErrInvalidSession
+// User resolves the owner of the access token verified by jwtauth.Verifier
+func User(ctx context.Context, db *db.DB) (*db.User, *Claims, error) {
+	token, tokenClaims, err := jwtauth.FromContext(ctx)
+	if err != nil || token == nil {
+		return nil, nil, ErrInvalidSession
 	}
 
-	dbUser, err := db.GetUser(userEmail)
+	claims, err := ParseClaims(tokenClaims)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, ErrInvalidSession
 	}
 
-	if dbUser == nil {
-		return nil, "", ErrInvalidEmailOrPassword
+	dbUser, err := db.GetUserByID(claims.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, ErrInvalidSession
+		}
+
+		return nil, nil, err
 	}
 
-	return dbUser, token.Subject(), nil
+	return dbUser, claims, nil
 }